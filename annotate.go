@@ -0,0 +1,39 @@
+package jsonpointerpos
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Annotate returns the document with a block comment inserted right before each value, which notes the JSON pointer
+// and the position of the value, e.g. `{"a": 1}` results in `/* "" 1:1 */ {"a": /* "/a" 1:7 */ 1}`. The positions
+// are the ones within the original document, computed with the options. It is a debugging aid to show what
+// GetAllPositions resolves, and the result can be parsed back with WithComments.
+func Annotate(document string, opts ...Option) (string, error) {
+	m, err := GetAllPositions(document, opts...)
+	if err != nil {
+		return "", err
+	}
+	base := newOptions(opts).baseOffset()
+	positions := make([]JSONPointerPosition, 0, len(m))
+	for _, pos := range m {
+		positions = append(positions, pos)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		return positions[i].Offset < positions[j].Offset
+	})
+
+	var sb strings.Builder
+	var last int64
+	for _, pos := range positions {
+		sb.WriteString(document[last : pos.Offset-base])
+		// The pointer is quoted, and can't end the comment early
+		ptr := strings.ReplaceAll(strconv.Quote(pos.Ptr.String()), "*/", `*\/`)
+		fmt.Fprintf(&sb, "/* %s %d:%d */ ", ptr, pos.Line, pos.Column)
+		last = pos.Offset - base
+	}
+	sb.WriteString(document[last:])
+	return sb.String(), nil
+}
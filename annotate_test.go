@@ -0,0 +1,47 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotate(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		opts   []Option
+		expect string
+	}{
+		{
+			name:   "scalar",
+			input:  `1`,
+			expect: `/* "" 1:1 */ 1`,
+		},
+		{
+			name:   "nested",
+			input:  "{\"a\": [1, true],\n \"b*\": {\"c\": null}}",
+			expect: "/* \"\" 1:1 */ {\"a\": /* \"/a\" 1:7 */ [/* \"/a/0\" 1:8 */ 1, /* \"/a/1\" 1:11 */ true],\n \"b*\": /* \"/b*\" 2:8 */ {\"c\": /* \"/b*\\/c\" 2:14 */ null}}",
+		},
+		{
+			name:   "zero based",
+			input:  `[1]`,
+			opts:   []Option{WithZeroBased()},
+			expect: `/* "" 0:0 */ [/* "/0" 0:1 */ 1]`,
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Annotate(tt.input, tt.opts...)
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, out)
+
+			// The result is still a valid document with comments
+			_, err = GetAllPositions(out, WithComments())
+			require.NoError(t, err)
+		})
+	}
+
+	_, err := Annotate(`{"a": }`)
+	require.Error(t, err)
+}
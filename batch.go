@@ -0,0 +1,61 @@
+package jsonpointerpos
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// BatchGetPositions is like GetPositions, but resolves the same pointers against many documents concurrently, with at
+// most workers goroutines. If workers is not positive, runtime.GOMAXPROCS(0) is used. The documents and the results
+// are keyed by the same keys, e.g. the file names.
+//
+// All the documents are processed even if some of them fail. The returned error joins the errors of all the failed
+// documents in the order of their keys, each of which is prefixed by the key. The results of the failed documents are
+// omitted, while the others are still returned.
+func BatchGetPositions(inputs map[string]string, ptrs []jsonpointer.Pointer, workers int, opts ...Option) (map[string]map[string]JSONPointerPosition, error) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	keys := make([]string, 0, len(inputs))
+	for k := range inputs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// Each document is resolved with its own clone of the compiled tree
+	c := Compile(ptrs)
+	results := make([]map[string]JSONPointerPosition, len(keys))
+	errs := make([]error, len(keys))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers && i < len(keys); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				results[idx], errs[idx] = c.Positions(inputs[keys[idx]], opts...)
+			}
+		}()
+	}
+	for i := range keys {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	out := make(map[string]map[string]JSONPointerPosition, len(keys))
+	var failed []error
+	for i, k := range keys {
+		if errs[i] != nil {
+			failed = append(failed, fmt.Errorf("%s: %w", k, errs[i]))
+			continue
+		}
+		out[k] = results[i]
+	}
+	return out, errors.Join(failed...)
+}
@@ -0,0 +1,43 @@
+package jsonpointerpos
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchGetPositions(t *testing.T) {
+	ptrs := []jsonpointer.Pointer{mustPointer("/a"), mustPointer("/b/0")}
+	inputs := map[string]string{}
+	for i := 0; i < 20; i++ {
+		inputs[fmt.Sprintf("doc%d.json", i)] = fmt.Sprintf(`{%*s"a": 1, "b": [%d]}`, i, "", i)
+	}
+
+	for _, workers := range []int{0, 1, 4, 100} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			out, err := BatchGetPositions(inputs, ptrs, workers)
+			require.NoError(t, err)
+			require.Len(t, out, len(inputs))
+			for k, input := range inputs {
+				expect, err := GetPositions(input, ptrs)
+				require.NoError(t, err)
+				require.Equal(t, expect, out[k])
+			}
+		})
+	}
+
+	inputs = map[string]string{
+		"ok.json":   `{"a": 1}`,
+		"bad1.json": `{"a": }`,
+		"bad2.json": `[1 2]`,
+	}
+	out, err := BatchGetPositions(inputs, ptrs, 2)
+	require.Error(t, err)
+	var perr *ParseError
+	require.ErrorAs(t, err, &perr)
+	require.Regexp(t, `^bad1.json: .*\nbad2.json: `, err.Error())
+	require.Len(t, out, 1)
+	require.Contains(t, out["ok.json"], "/a")
+}
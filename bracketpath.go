@@ -0,0 +1,65 @@
+package jsonpointerpos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// GetPositionsBracket is like GetPositions, but the values are specified by the paths in the bracket notation, see
+// ParseBracketPath. The result is keyed by the JSON pointers of the paths, e.g. "/servers/0/config/weird.key" for
+// `servers[0].config["weird.key"]`.
+func GetPositionsBracket(document string, paths []string, opts ...Option) (map[string]JSONPointerPosition, error) {
+	return GetPositionsPaths(document, paths, BracketPathParser, opts...)
+}
+
+// ParseBracketPath converts the path in the bracket notation of JavaScript, e.g. `servers[0].config["weird.key"]`,
+// to the JSON pointer "/servers/0/config/weird.key". The path is a sequence of the following segments:
+//
+//   - Member names, which are preceded by a dot except at the start, e.g. "a.b".
+//   - Quoted member names in brackets, e.g. `["a.b"]` or "['a.b']", which can contain any character, with the quote
+//     and the backslash escaped by a backslash.
+//   - Indexes in brackets, e.g. "[0]", using non-negative indexes only.
+//   - Wildcards (".*" or "[*]"), which match any object member or array element.
+//
+// The empty path is the root. It's the same as the JSONPath supported by GetPositionsJSONPath, without the leading "$"
+// and the recursive descent.
+//
+// A wildcard is converted to the reference token "*", which only acts as a wildcard with WithWildcards, and is then
+// indistinguishable from a quoted member name of "*". GetPositionsBracket and BracketPathParser keep them apart, so
+// that `["*"]` always points to the member "*" and "[*]" is always a wildcard.
+func ParseBracketPath(path string) (jsonpointer.Pointer, error) {
+	ptr, err := parseBracketPath(path)
+	if err != nil {
+		return jsonpointer.Pointer{}, err
+	}
+	return literal(ptr), nil
+}
+
+// parseBracketPath is like ParseBracketPath, but the wildcards are converted to the patterns.
+func parseBracketPath(path string) (jsonpointer.Pointer, error) {
+	var tks []string
+	s := path
+	for len(s) != 0 {
+		var (
+			tk  string
+			err error
+		)
+		switch {
+		case strings.HasPrefix(s, "["):
+			tk, s, err = parseJSONPathBracket(s)
+		case strings.HasPrefix(s, ".") && len(tks) != 0:
+			tk, s, err = parseJSONPathName(s[1:])
+		case len(tks) == 0:
+			tk, s, err = parseJSONPathName(s)
+		default:
+			err = fmt.Errorf("unexpected %q", s)
+		}
+		if err != nil {
+			return jsonpointer.Pointer{}, fmt.Errorf("invalid bracket path %q: %v", path, err)
+		}
+		tks = append(tks, tk)
+	}
+	return NewPointer(tks...), nil
+}
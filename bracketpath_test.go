@@ -0,0 +1,60 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBracketPath(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+		err    bool
+	}{
+		{input: "", expect: ""},
+		{input: `servers[0].config["weird.key"]`, expect: "/servers/0/config/weird.key"},
+		{input: `['a/b~c']["d\"e"]`, expect: `/a~1b~0c/d"e`},
+		{input: "[1][2]", expect: "/1/2"},
+		{input: "a[*].*", expect: "/a/*/*"},
+		{input: "a.0", expect: "/a/0"},
+		{input: ".a", err: true},
+		{input: "a..b", err: true},
+		{input: "a.", err: true},
+		{input: "a[-1]", err: true},
+		{input: "a['b'", err: true},
+		{input: "a[b]", err: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.input, func(t *testing.T) {
+			ptr, err := ParseBracketPath(tt.input)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, ptr.String())
+		})
+	}
+}
+
+func TestGetPositionsBracket(t *testing.T) {
+	input := `{"servers": [{"config": {"weird.key": 1}}]}`
+	out, err := GetPositionsBracket(input, []string{`servers[0].config["weird.key"]`})
+	require.NoError(t, err)
+	require.Equal(t, Position{Line: 1, Column: 39, Offset: 38}, out["/servers/0/config/weird.key"].Position)
+
+	_, err = GetPositionsBracket(input, []string{"servers[x]"})
+	require.Error(t, err)
+
+	// A quoted "*" is a literal member name, while an unquoted one is a wildcard
+	input = `{"*": 1, "a": 2}`
+	out, err = GetPositionsBracket(input, []string{`["*"]`})
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	require.Equal(t, Position{Line: 1, Column: 7, Offset: 6}, out["/*"].Position)
+	out, err = GetPositionsBracket(input, []string{"[*]"})
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	require.Equal(t, Position{Line: 1, Column: 15, Offset: 14}, out["/a"].Position)
+}
@@ -0,0 +1,147 @@
+package jsonpointerpos
+
+import (
+	"sort"
+	"strings"
+)
+
+// comment is a comment of the document, along with the significant bytes around it, i.e. those that are neither
+// whitespaces nor within a comment.
+type comment struct {
+	text  string
+	start int64
+	// prev is the offset of the last significant byte before the comment, or -1 if there is none, and prevByte is the
+	// byte. prevPrev is the offset of the significant byte before prev, or -1 if there is none.
+	prev, prevPrev int64
+	prevByte       byte
+	// next is the offset of the first significant byte after the comment, or -1 if it's not read yet.
+	next int64
+	// breaksBefore is the number of the line breaks between the comment and the preceding comment or significant
+	// byte, and breaksAfter is the one between the comment and the following one.
+	breaksBefore, breaksAfter int
+}
+
+// commentList records the comments masked by the masker in the document order. All its methods are no-ops on a nil
+// list.
+type commentList struct {
+	comments []comment
+	// pending is the index of the first comment whose next significant byte is not read yet.
+	pending int
+	// text is the text of the comment being read, if any.
+	text           *strings.Builder
+	prev, prevPrev int64
+	prevByte       byte
+	breaks         int
+}
+
+func newCommentList() *commentList {
+	return &commentList{prev: -1, prevPrev: -1}
+}
+
+// begin begins a comment at the offset, which starts with the marker, i.e. "//" or "/*".
+func (l *commentList) begin(offset int64, marker []byte) {
+	if l == nil {
+		return
+	}
+	if n := len(l.comments); n > l.pending {
+		l.comments[n-1].breaksAfter = l.breaks
+	}
+	l.comments = append(l.comments, comment{
+		start:        offset,
+		prev:         l.prev,
+		prevPrev:     l.prevPrev,
+		prevByte:     l.prevByte,
+		next:         -1,
+		breaksBefore: l.breaks,
+	})
+	l.breaks = 0
+	l.text = &strings.Builder{}
+	l.text.Write(marker)
+}
+
+// write writes a byte of the comment being read.
+func (l *commentList) write(c byte) {
+	if l == nil {
+		return
+	}
+	l.text.WriteByte(c)
+}
+
+// end ends the comment being read.
+func (l *commentList) end() {
+	if l == nil {
+		return
+	}
+	l.comments[len(l.comments)-1].text = l.text.String()
+	l.text = nil
+}
+
+// lineBreak records a line break outside the comments and the strings.
+func (l *commentList) lineBreak() {
+	if l == nil {
+		return
+	}
+	l.breaks++
+}
+
+// significant records the significant byte c at the offset. The bytes within the strings can be omitted, except the
+// closing quotes.
+func (l *commentList) significant(offset int64, c byte) {
+	if l == nil {
+		return
+	}
+	if n := len(l.comments); n > l.pending {
+		l.comments[n-1].breaksAfter = l.breaks
+		for i := l.pending; i < n; i++ {
+			l.comments[i].next = offset
+		}
+		l.pending = n
+	}
+	l.prevPrev, l.prev, l.prevByte = l.prev, offset, c
+	l.breaks = 0
+}
+
+// leading returns the comments right before the value (or the key of the member) that starts at the offset. Those
+// on the same line as the preceding value are its trailing ones instead, and a blank line breaks the association, so
+// that only the comments after the last blank line are returned.
+func (l *commentList) leading(start int64) []string {
+	i := sort.Search(len(l.comments), func(i int) bool { return l.comments[i].start >= start })
+	g := i
+	for g > 0 && l.comments[g-1].next == start {
+		g--
+	}
+	if g == i {
+		return nil
+	}
+	if c := l.comments[g]; c.prev >= 0 && c.prevByte != '{' && c.prevByte != '[' {
+		for g < i && l.comments[g].breaksBefore == 0 {
+			g++
+		}
+	}
+	j := i
+	for j > g && l.comments[j-1].breaksAfter <= 1 {
+		j--
+	}
+	if j == i {
+		return nil
+	}
+	out := make([]string, 0, i-j)
+	for _, c := range l.comments[j:i] {
+		out = append(out, c.text)
+	}
+	return out
+}
+
+// trailing returns the comment on the same line after the value that ends at the offset, which can follow the comma
+// after the value.
+func (l *commentList) trailing(end int64) string {
+	i := sort.Search(len(l.comments), func(i int) bool { return l.comments[i].start > end })
+	if i == len(l.comments) {
+		return ""
+	}
+	c := l.comments[i]
+	if c.breaksBefore != 0 || !(c.prev == end || c.prevByte == ',' && c.prevPrev == end) {
+		return ""
+	}
+	return c.text
+}
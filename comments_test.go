@@ -0,0 +1,77 @@
+package jsonpointerpos
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPositionsCommentTrivia(t *testing.T) {
+	input := `// the config
+{ // about a
+  "a": 1, // trailing a
+  // detached
+
+  // about b
+  /* and more */
+  "b": "// not a comment", /* trailing b */ // not trailing
+
+  "c": [
+    // first
+    true,
+    null /* trailing null */
+  ],
+  "d": {}
+
+  // dangling
+} // end
+`
+	expect := map[string]struct {
+		leading  []string
+		trailing string
+	}{
+		"":     {leading: []string{"// the config"}, trailing: "// end"},
+		"/a":   {leading: []string{"// about a"}, trailing: "// trailing a"},
+		"/b":   {leading: []string{"// about b", "/* and more */"}, trailing: "/* trailing b */"},
+		"/c":   {},
+		"/c/0": {leading: []string{"// first"}},
+		"/c/1": {trailing: "/* trailing null */"},
+		"/d":   {},
+	}
+	var ptrs []jsonpointer.Pointer
+	for k := range expect {
+		ptrs = append(ptrs, mustPointer(k))
+	}
+	for _, r := range []func() io.Reader{
+		func() io.Reader { return strings.NewReader(input) },
+		func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+	} {
+		out, err := GetPositionsReader(r(), ptrs, WithComments())
+		require.NoError(t, err)
+		for k, v := range expect {
+			require.Equal(t, v.leading, out[k].LeadingComments, k)
+			require.Equal(t, v.trailing, out[k].TrailingComment, k)
+		}
+	}
+
+	// The comments are not recorded without the option
+	out, err := GetPositions(`{"a": 1}`, []jsonpointer.Pointer{mustPointer("/a")})
+	require.NoError(t, err)
+	require.Nil(t, out["/a"].LeadingComments)
+}
+
+func TestGetPositionsCommentTriviaJSON5(t *testing.T) {
+	input := "{\r\n  // the key\r\n  key: 'it\\'s', // after\r\n  'x//': [1,/* one */ 2],\r\n}"
+	all, err := GetAllPositions(input, WithJSON5())
+	require.NoError(t, err)
+	require.Equal(t, []string{"// the key"}, all["/key"].LeadingComments)
+	require.Equal(t, "// after", all["/key"].TrailingComment)
+	require.Nil(t, all["/x~1~1"].LeadingComments)
+	require.Empty(t, all["/x~1~1"].TrailingComment)
+	require.Equal(t, "/* one */", all["/x~1~1/0"].TrailingComment)
+	require.Nil(t, all["/x~1~1/1"].LeadingComments)
+}
@@ -0,0 +1,43 @@
+package jsonpointerpos
+
+import (
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// Compiled is a set of JSON pointers compiled for resolving against many documents.
+// It is safe for concurrent use.
+type Compiled struct {
+	ptrs []jsonpointer.Pointer
+	tree tokenTree
+	// patterns and wildcardTree are the pointers and the tree with the wildcards, for the WithWildcards option.
+	patterns     []jsonpointer.Pointer
+	wildcardTree tokenTree
+}
+
+// Compile compiles the pointers, so that they can be resolved against many documents without being rebuilt each time.
+func Compile(ptrs []jsonpointer.Pointer) *Compiled {
+	patterns := options{wildcards: true}.patterns(ptrs)
+	return &Compiled{
+		ptrs:         append([]jsonpointer.Pointer(nil), ptrs...),
+		tree:         buildTokenTree(ptrs),
+		patterns:     patterns,
+		wildcardTree: buildTokenTree(patterns),
+	}
+}
+
+// Positions is like GetPositions, with the compiled pointers.
+func (c *Compiled) Positions(document string, opts ...Option) (map[string]JSONPointerPosition, error) {
+	if len(c.ptrs) == 0 {
+		return nil, nil
+	}
+	o := newOptions(opts)
+	ptrs, tree := c.ptrs, &c.tree
+	if o.wildcards {
+		ptrs, tree = c.patterns, &c.wildcardTree
+	}
+	// The template tree is never walked directly, so that no state leaks between calls.
+	w := newWalker(strings.NewReader(document), o)
+	return w.positions(tree.clone(), ptrs)
+}
@@ -0,0 +1,43 @@
+package jsonpointerpos
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompiled(t *testing.T) {
+	var ptrs []jsonpointer.Pointer
+	for _, v := range []string{"/a", "/b/0"} {
+		ptr, err := jsonpointer.New(v)
+		require.NoError(t, err)
+		ptrs = append(ptrs, ptr)
+	}
+	c := Compile(ptrs)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			// Each document has the pointed values at different offsets, and only the even ones have "/b/0".
+			input := fmt.Sprintf(`{%*s"a": 1}`, i, "")
+			if i%2 == 0 {
+				input = fmt.Sprintf(`{%*s"a": 1, "b": [2]}`, i, "")
+			}
+			expect, err := GetPositions(input, ptrs)
+			require.NoError(t, err)
+			out, err := c.Positions(input)
+			require.NoError(t, err)
+			require.Equal(t, expect, out)
+		}()
+	}
+	wg.Wait()
+
+	// The template tree is left untouched
+	require.Equal(t, buildTokenTree(ptrs), c.tree)
+}
@@ -0,0 +1,125 @@
+package jsonpointerpos
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// OffsetToPosition returns the position of the byte offset within the document, which is counted in the same way as
+// the positions returned with the same options. The offset is clamped to the range of the document, and is expected
+// to be at the start of a character. It scans the document each time, use LineIndex for many conversions.
+func OffsetToPosition(document string, offset int64, opts ...Option) Position {
+	return NewLineIndex(document, opts...).Position(offset)
+}
+
+// PositionToOffset returns the byte offset of the position within the document, i.e. the inverse of
+// OffsetToPosition. Only the Line and Column of the position are used. If several offsets are at the position, e.g.
+// those before and after a leading BOM, the last one is returned. An error is returned if there is no character at the
+// position, e.g. a column beyond the end of the line, or within a tab. It scans the document each time, use LineIndex
+// for many conversions.
+func PositionToOffset(document string, pos Position, opts ...Option) (int64, error) {
+	return NewLineIndex(document, opts...).Offset(pos)
+}
+
+// LineIndex converts between the byte offsets and the positions of a document, like OffsetToPosition and
+// PositionToOffset. The line starts are indexed once, so that each conversion only binary searches the line and scans
+// it, instead of the whole document. It is safe for concurrent use. GetPositions doesn't use it, as the positions it
+// returns are already resolved in a single scan along with the walk, however many pointers there are.
+type LineIndex struct {
+	document string
+	data     []byte
+	opts     options
+	// starts is the offsets of the line starts, where starts[0] is 0.
+	starts []int
+}
+
+// NewLineIndex indexes the line starts of the document, where the positions are counted with the options.
+func NewLineIndex(document string, opts ...Option) *LineIndex {
+	o := newOptions(opts)
+	starts := []int{0}
+	for i := 0; i < len(document); i++ {
+		switch document[i] {
+		case '\r':
+			// CRLF is a single line break
+			if i+1 < len(document) && document[i+1] == '\n' {
+				i++
+			}
+			starts = append(starts, i+1)
+		case '\n':
+			starts = append(starts, i+1)
+		case 0xE2:
+			// U+2028 and U+2029 are encoded as E2 80 A8 and E2 80 A9
+			if o.unicodeLineTerminators && i+2 < len(document) && document[i+1] == 0x80 && (document[i+2] == 0xA8 || document[i+2] == 0xA9) {
+				i += 2
+				starts = append(starts, i+1)
+			}
+		}
+	}
+	return &LineIndex{
+		document: document,
+		data:     []byte(document),
+		opts:     o,
+		starts:   starts,
+	}
+}
+
+// line returns the positioner that starts from the line of the index i, which is 0-based.
+func (idx *LineIndex) line(i int) *positioner {
+	p := newPositioner(nil, idx.opts)
+	p.buf = idx.data[idx.starts[i]:]
+	p.offset = int64(idx.starts[i])
+	line, _ := idx.opts.start()
+	p.line = line + i
+	// Only the first line starts from the base column
+	if i > 0 {
+		p.column = 1
+	}
+	return p
+}
+
+// Position is like OffsetToPosition.
+func (idx *LineIndex) Position(offset int64) Position {
+	offset -= idx.opts.baseOffset()
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > int64(len(idx.document)) {
+		offset = int64(len(idx.document))
+	}
+	// The line that contains the offset is the last one that starts before or at it
+	i := sort.SearchInts(idx.starts, int(offset)+1) - 1
+	return idx.line(i).position(offset)
+}
+
+// Offset is like PositionToOffset.
+func (idx *LineIndex) Offset(pos Position) (int64, error) {
+	line, _ := idx.opts.start()
+	i := pos.Line - line
+	if idx.opts.zeroBased {
+		i++
+	}
+	if i < 0 || i >= len(idx.starts) {
+		return 0, fmt.Errorf("position %d:%d doesn't exist in the document", pos.Line, pos.Column)
+	}
+	p := idx.line(i)
+	var offset int64 = -1
+	for off := int64(idx.starts[i]); ; {
+		cur := p.position(off)
+		if cur.Line > pos.Line || cur.Line == pos.Line && cur.Column > pos.Column {
+			break
+		}
+		if cur.Line == pos.Line && cur.Column == pos.Column {
+			offset = off
+		}
+		if off == int64(len(idx.document)) {
+			break
+		}
+		_, size := utf8.DecodeRuneInString(idx.document[off:])
+		off += int64(size)
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("position %d:%d doesn't exist in the document", pos.Line, pos.Column)
+	}
+	return offset + idx.opts.baseOffset(), nil
+}
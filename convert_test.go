@@ -0,0 +1,171 @@
+package jsonpointerpos
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffsetToPosition(t *testing.T) {
+	input := "\uFEFF{\r\n\t\"😀\": 1,\r\"b\": 2}"
+	cases := []struct {
+		name   string
+		offset int64
+		opts   []Option
+		expect Position
+	}{
+		{
+			name:   "leading BOM",
+			offset: 0,
+			expect: Position{Line: 1, Column: 1, Offset: 0},
+		},
+		{
+			name:   "after BOM",
+			offset: 3,
+			expect: Position{Line: 1, Column: 1, Offset: 3},
+		},
+		{
+			name:   "after CRLF",
+			offset: 6,
+			expect: Position{Line: 2, Column: 1, Offset: 6},
+		},
+		{
+			name:   "tab width",
+			offset: 7,
+			opts:   []Option{WithTabWidth(4)},
+			expect: Position{Line: 2, Column: 5, Offset: 7},
+		},
+		{
+			name:   "after emoji in runes",
+			offset: 12,
+			expect: Position{Line: 2, Column: 4, Offset: 12},
+		},
+		{
+			name:   "after emoji in UTF-16",
+			offset: 12,
+			opts:   []Option{WithUTF16Columns()},
+			expect: Position{Line: 2, Column: 5, Offset: 12},
+		},
+		{
+			name:   "after lone CR zero based",
+			offset: 18,
+			opts:   []Option{WithZeroBased()},
+			expect: Position{Line: 2, Column: 0, Offset: 18},
+		},
+		{
+			name:   "clamped",
+			offset: 100,
+			expect: Position{Line: 3, Column: 8, Offset: 25},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := OffsetToPosition(input, tt.offset, tt.opts...)
+			require.Equal(t, tt.expect, pos)
+
+			offset, err := PositionToOffset(input, pos, tt.opts...)
+			require.NoError(t, err)
+			// The BOM shares the position with the first character
+			if tt.offset == 0 {
+				require.Equal(t, int64(3), offset)
+				return
+			}
+			require.Equal(t, pos.Offset, offset)
+		})
+	}
+}
+
+func TestPositionToOffsetConsistency(t *testing.T) {
+	input := "{\n  \"a\": [1, \"é\"],\r\n\t\"b\": {\"c\": null}\n}"
+	for _, opts := range [][]Option{nil, {WithZeroBased()}, {WithUTF16Columns(), WithTabWidth(8)}} {
+		m, err := GetAllPositions(input, opts...)
+		require.NoError(t, err)
+		for k, pos := range m {
+			require.Equal(t, pos.Position, OffsetToPosition(input, pos.Offset, opts...), k)
+			offset, err := PositionToOffset(input, pos.Position, opts...)
+			require.NoError(t, err)
+			require.Equal(t, pos.Offset, offset, k)
+		}
+	}
+}
+
+func TestPositionToOffsetError(t *testing.T) {
+	input := "{\n\t\"a\": 1}"
+	for _, pos := range []Position{
+		{Line: 1, Column: 3},
+		{Line: 3, Column: 1},
+		{Line: 2, Column: 3},
+	} {
+		_, err := PositionToOffset(input, pos, WithTabWidth(4))
+		require.Error(t, err)
+	}
+	offset, err := PositionToOffset(input, Position{Line: 1, Column: 2})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), offset)
+}
+
+func TestLineIndex(t *testing.T) {
+	input := "{\r\n  \"a\": [1,\r\"é\"],\n\n  \"b\": {\"c\": null}\n}\n"
+	for _, opts := range [][]Option{nil, {WithZeroBased()}, {WithUTF16Columns(), WithTabWidth(8)}} {
+		idx := NewLineIndex(input, opts...)
+		p := newPositioner(nil, newOptions(opts))
+		p.buf = []byte(input)
+		for offset := int64(0); offset <= int64(len(input)); offset++ {
+			// The sequential scan is the reference
+			expect := p.position(offset)
+			require.Equal(t, expect, idx.Position(offset), offset)
+		}
+	}
+}
+
+// benchmarkDocument returns a document of n lines, and the offsets of the values at the end of each line.
+func benchmarkDocument(n int) (string, []int64) {
+	var sb strings.Builder
+	var offsets []int64
+	sb.WriteString("[\n")
+	for i := 0; i < n; i++ {
+		sb.WriteString("  {\"key\": \"value\", \"n\": ")
+		offsets = append(offsets, int64(sb.Len()))
+		fmt.Fprintf(&sb, "%d},\n", i)
+	}
+	sb.WriteString("  null\n]")
+	return sb.String(), offsets
+}
+
+func BenchmarkOffsetToPosition(b *testing.B) {
+	input, offsets := benchmarkDocument(5000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, offset := range offsets {
+			OffsetToPosition(input, offset)
+		}
+	}
+}
+
+func BenchmarkLineIndexPosition(b *testing.B) {
+	input, offsets := benchmarkDocument(5000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idx := NewLineIndex(input)
+		for _, offset := range offsets {
+			idx.Position(offset)
+		}
+	}
+}
+
+func BenchmarkGetPositionsManyPointers(b *testing.B) {
+	input, _ := benchmarkDocument(5000)
+	var ptrs []jsonpointer.Pointer
+	for i := 0; i < 5000; i++ {
+		ptrs = append(ptrs, mustPointer(fmt.Sprintf("/%d/n", i)))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetPositions(input, ptrs); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package jsonpointerpos
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// DiffKind is the kind of the change of a value between two documents.
+type DiffKind int
+
+const (
+	// DiffChanged is a value that exists in both documents with different values, which are either scalars or of
+	// different kinds.
+	DiffChanged DiffKind = iota
+	// DiffAdded is a value that only exists in the new document.
+	DiffAdded
+	// DiffRemoved is a value that only exists in the old document.
+	DiffRemoved
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case DiffChanged:
+		return "changed"
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	default:
+		return "unknown"
+	}
+}
+
+// PointerDiff is a value that differs between two documents.
+type PointerDiff struct {
+	Ptr  jsonpointer.Pointer
+	Kind DiffKind
+	// OldPosition is the position of the value in the old document, which is nil if the value is added.
+	OldPosition *Position
+	// NewPosition is the position of the value in the new document, which is nil if the value is removed.
+	NewPosition *Position
+}
+
+// DiffPositions compares the values of the two documents, and returns the pointers of the values that differ, along
+// with their positions in the documents they exist in. The comparison is value-based, where the numbers are compared by
+// their source text as json.Number, e.g. 1 and 1.0 differ.
+//
+// Only the outermost differing values are reported: the objects (or the arrays) in both documents are compared by
+// their members (or their elements) instead, and the members/elements of an added or removed value are not reported on
+// their own. The array elements are compared by their indexes, so that an element inserted in the middle changes
+// all the following ones. The diffs are ordered by the pointers, where the object members are sorted by their keys and
+// the array elements by their indexes.
+func DiffPositions(oldInput, newInput string, opts ...Option) ([]PointerDiff, error) {
+	root := []jsonpointer.Pointer{{}}
+	oldValues, err := GetPositionsWithValues(oldInput, root, opts...)
+	if err != nil {
+		return nil, err
+	}
+	newValues, err := GetPositionsWithValues(newInput, root, opts...)
+	if err != nil {
+		return nil, err
+	}
+	oldPositions, err := GetAllPositions(oldInput, opts...)
+	if err != nil {
+		return nil, err
+	}
+	newPositions, err := GetAllPositions(newInput, opts...)
+	if err != nil {
+		return nil, err
+	}
+	d := differ{oldPositions: oldPositions, newPositions: newPositions}
+	d.diff(nil, oldValues[""].Value, newValues[""].Value)
+	return d.diffs, nil
+}
+
+// differ compares the decoded values of two documents recursively, and collects the diffs.
+type differ struct {
+	oldPositions, newPositions map[string]JSONPointerPosition
+	diffs                      []PointerDiff
+}
+
+func (d *differ) diff(tks []string, oldValue, newValue any) {
+	switch oldValue := oldValue.(type) {
+	case map[string]any:
+		if newValue, ok := newValue.(map[string]any); ok {
+			keys := make([]string, 0, len(oldValue)+len(newValue))
+			for k := range oldValue {
+				keys = append(keys, k)
+			}
+			for k := range newValue {
+				if _, ok := oldValue[k]; !ok {
+					keys = append(keys, k)
+				}
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				d.member(append(tks[:len(tks):len(tks)], k), oldValue, newValue, k)
+			}
+			return
+		}
+	case []any:
+		if newValue, ok := newValue.([]any); ok {
+			for i := 0; i < len(oldValue) || i < len(newValue); i++ {
+				childTks := append(tks[:len(tks):len(tks)], strconv.Itoa(i))
+				switch {
+				case i >= len(newValue):
+					d.add(childTks, DiffRemoved)
+				case i >= len(oldValue):
+					d.add(childTks, DiffAdded)
+				default:
+					d.diff(childTks, oldValue[i], newValue[i])
+				}
+			}
+			return
+		}
+	default:
+		// The scalars are json.Number, string, bool or nil, which are comparable
+		switch newValue.(type) {
+		case map[string]any, []any:
+		default:
+			if oldValue == newValue {
+				return
+			}
+		}
+	}
+	d.add(tks, DiffChanged)
+}
+
+// member compares the member of the key of the objects, which might only exist in one of them.
+func (d *differ) member(tks []string, oldObject, newObject map[string]any, key string) {
+	oldValue, inOld := oldObject[key]
+	newValue, inNew := newObject[key]
+	switch {
+	case !inNew:
+		d.add(tks, DiffRemoved)
+	case !inOld:
+		d.add(tks, DiffAdded)
+	default:
+		d.diff(tks, oldValue, newValue)
+	}
+}
+
+// add adds the diff of the tokens, with the positions of the documents that the value exists in.
+func (d *differ) add(tks []string, kind DiffKind) {
+	diff := PointerDiff{Ptr: NewPointer(tks...), Kind: kind}
+	if pos, ok := d.oldPositions[diff.Ptr.String()]; ok && kind != DiffAdded {
+		diff.OldPosition = &pos.Position
+	}
+	if pos, ok := d.newPositions[diff.Ptr.String()]; ok && kind != DiffRemoved {
+		diff.NewPosition = &pos.Position
+	}
+	d.diffs = append(d.diffs, diff)
+}
@@ -0,0 +1,83 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffPositions(t *testing.T) {
+	oldInput := `{
+  "a": 1,
+  "b": {"c": "x", "d": [1, 2, 3]},
+  "e": null,
+  "f": 1.0
+}`
+	newInput := `{"a": 1, "b": {"c": "y", "d": [1, 2]}, "e": [], "f": 1, "g": {"h": true}}`
+	diffs, err := DiffPositions(oldInput, newInput)
+	require.NoError(t, err)
+	require.Equal(t, []PointerDiff{
+		{
+			Ptr:         mustPointer("/b/c"),
+			Kind:        DiffChanged,
+			OldPosition: &Position{Line: 3, Column: 14, Offset: 25},
+			NewPosition: &Position{Line: 1, Column: 21, Offset: 20},
+		},
+		{
+			Ptr:         mustPointer("/b/d/2"),
+			Kind:        DiffRemoved,
+			OldPosition: &Position{Line: 3, Column: 31, Offset: 42},
+		},
+		{
+			Ptr:         mustPointer("/e"),
+			Kind:        DiffChanged,
+			OldPosition: &Position{Line: 4, Column: 8, Offset: 54},
+			NewPosition: &Position{Line: 1, Column: 45, Offset: 44},
+		},
+		{
+			Ptr:         mustPointer("/f"),
+			Kind:        DiffChanged,
+			OldPosition: &Position{Line: 5, Column: 8, Offset: 67},
+			NewPosition: &Position{Line: 1, Column: 54, Offset: 53},
+		},
+		{
+			Ptr:         mustPointer("/g"),
+			Kind:        DiffAdded,
+			NewPosition: &Position{Line: 1, Column: 62, Offset: 61},
+		},
+	}, diffs)
+	require.Equal(t, "removed", diffs[1].Kind.String())
+
+	diffs, err = DiffPositions(`[1, {"a": 2}]`, " [1, {\"a\": 2}]\n")
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+
+	diffs, err = DiffPositions(`1`, `"1"`)
+	require.NoError(t, err)
+	require.Equal(t, []PointerDiff{{Ptr: mustPointer(""), Kind: DiffChanged, OldPosition: &Position{Line: 1, Column: 1}, NewPosition: &Position{Line: 1, Column: 1}}}, diffs)
+
+	_, err = DiffPositions(`{}`, `{`)
+	require.Error(t, err)
+}
+
+func TestDiffPositionsDuplicateKeys(t *testing.T) {
+	oldInput := `{"a": 1, "a": 2}`
+	newInput := `{"a": 2, "a": 1}`
+	diffs, err := DiffPositions(oldInput, newInput)
+	require.NoError(t, err)
+	require.Equal(t, []PointerDiff{{
+		Ptr:         mustPointer("/a"),
+		Kind:        DiffChanged,
+		OldPosition: &Position{Line: 1, Column: 15, Offset: 14},
+		NewPosition: &Position{Line: 1, Column: 15, Offset: 14},
+	}}, diffs)
+
+	// The values and their positions are both of the first occurrence with the option
+	diffs, err = DiffPositions(`{"a": 1, "a": 2}`, `{"a": 1, "a": 3}`, WithFirstDuplicateKey())
+	require.NoError(t, err)
+	require.Empty(t, diffs)
+	diffs, err = DiffPositions(oldInput, newInput, WithFirstDuplicateKey())
+	require.NoError(t, err)
+	require.Len(t, diffs, 1)
+	require.Equal(t, &Position{Line: 1, Column: 7, Offset: 6}, diffs[0].OldPosition)
+}
@@ -0,0 +1,68 @@
+package jsonpointerpos
+
+import (
+	"sort"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// Document is a document parsed once for repeated position queries, like go/token.FileSet. It holds the positions
+// of all the values and the line starts of the document, so that each query doesn't read the document again.
+// It is safe for concurrent use.
+type Document struct {
+	positions map[string]JSONPointerPosition
+	// sorted is the positions in document order.
+	sorted []JSONPointerPosition
+	index  *LineIndex
+}
+
+// Parse parses the document with the options, which apply to all the positions returned by the Document.
+func Parse(document string, opts ...Option) (*Document, error) {
+	m, err := GetAllPositions(document, opts...)
+	if err != nil {
+		return nil, err
+	}
+	sorted := make([]JSONPointerPosition, 0, len(m))
+	for _, v := range m {
+		sorted = append(sorted, v)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Offset < sorted[j].Offset
+	})
+	return &Document{
+		positions: m,
+		sorted:    sorted,
+		index:     NewLineIndex(document, opts...),
+	}, nil
+}
+
+// PositionOf returns the position of the value that the pointer points to, like GetPosition. The returned bool
+// reports whether the pointer exists in the document.
+func (d *Document) PositionOf(ptr jsonpointer.Pointer) (JSONPointerPosition, bool) {
+	pos, ok := d.positions[ptr.String()]
+	return pos, ok
+}
+
+// PointerAt is like PointerAt, without reading the document again.
+func (d *Document) PointerAt(pos Position) (jsonpointer.Pointer, bool) {
+	return pointerAt(d.positions, pos)
+}
+
+// AllPositions is like GetAllPositions. The returned map is a copy, which can be modified by the caller.
+func (d *Document) AllPositions() map[string]JSONPointerPosition {
+	out := make(map[string]JSONPointerPosition, len(d.positions))
+	for k, v := range d.positions {
+		out[k] = v
+	}
+	return out
+}
+
+// Position is like OffsetToPosition.
+func (d *Document) Position(offset int64) Position {
+	return d.index.Position(offset)
+}
+
+// Offset is like PositionToOffset.
+func (d *Document) Offset(pos Position) (int64, error) {
+	return d.index.Offset(pos)
+}
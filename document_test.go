@@ -0,0 +1,54 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocument(t *testing.T) {
+	input := "{\n  \"a\": {\"b\": [1, \"xy\"]},\n  \"c\": true\n}"
+	doc, err := Parse(input)
+	require.NoError(t, err)
+
+	all, err := GetAllPositions(input)
+	require.NoError(t, err)
+	require.Equal(t, all, doc.AllPositions())
+	// The returned map doesn't share the state of the document
+	delete(doc.AllPositions(), "/a")
+
+	pos, ok := doc.PositionOf(mustPointer("/a/b/1"))
+	require.True(t, ok)
+	require.Equal(t, all["/a/b/1"], pos)
+	_, ok = doc.PositionOf(mustPointer("/a/x"))
+	require.False(t, ok)
+
+	ptr, ok := doc.PointerAt(Position{Line: 2, Column: 10})
+	require.True(t, ok)
+	require.Equal(t, "/a/b", ptr.String())
+	_, ok = doc.PointerAt(Position{Line: 5, Column: 1})
+	require.False(t, ok)
+
+	require.Equal(t, pos.Position, doc.Position(pos.Offset))
+	offset, err := doc.Offset(pos.Position)
+	require.NoError(t, err)
+	require.Equal(t, pos.Offset, offset)
+
+	_, err = Parse(`{"a": }`)
+	require.Error(t, err)
+}
+
+func TestDocumentOptions(t *testing.T) {
+	input := "{\"a\":\t\"😀\"}"
+	doc, err := Parse(input, WithZeroBased(), WithUTF16Columns(), WithTabWidth(4))
+	require.NoError(t, err)
+
+	pos, ok := doc.PositionOf(mustPointer("/a"))
+	require.True(t, ok)
+	require.Equal(t, Position{Line: 0, Column: 8, Offset: 6}, pos.Position)
+	require.Equal(t, pos.EndPosition, doc.Position(pos.Offset+5))
+
+	ptr, ok := doc.PointerAt(Position{Line: 0, Column: 10})
+	require.True(t, ok)
+	require.Equal(t, "/a", ptr.String())
+}
@@ -0,0 +1,49 @@
+package jsonpointerpos
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// GetPositionsDot is like GetPositions, but the values are specified by the paths in the dot notation, see
+// ParseDotPath. The result is keyed by the JSON pointers of the paths, e.g. "/servers/0/port" for "servers.0.port".
+func GetPositionsDot(document string, paths []string, opts ...Option) (map[string]JSONPointerPosition, error) {
+	return GetPositionsPaths(document, paths, DotPathParser, opts...)
+}
+
+// ParseDotPath converts the path in the dot notation, e.g. "servers.0.port", to the JSON pointer "/servers/0/port".
+// Each segment is a reference token as is, so that a number is either an array index or an object key. A literal dot
+// or backslash within a key is escaped by a backslash, e.g. `a\.b` is the key "a.b". The empty path is the root, while
+// an empty segment is rejected.
+func ParseDotPath(path string) (jsonpointer.Pointer, error) {
+	if path == "" {
+		return jsonpointer.Pointer{}, nil
+	}
+	var tks []string
+	var sb strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '\\':
+			i++
+			if i == len(path) || path[i] != '.' && path[i] != '\\' {
+				return jsonpointer.Pointer{}, fmt.Errorf("invalid dot path %q: invalid escape at %d", path, i-1)
+			}
+			sb.WriteByte(path[i])
+		case '.':
+			if sb.Len() == 0 {
+				return jsonpointer.Pointer{}, fmt.Errorf("invalid dot path %q: empty segment at %d", path, i)
+			}
+			tks = append(tks, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	if sb.Len() == 0 {
+		return jsonpointer.Pointer{}, fmt.Errorf("invalid dot path %q: empty segment at %d", path, len(path))
+	}
+	tks = append(tks, sb.String())
+	return NewPointer(tks...), nil
+}
@@ -0,0 +1,49 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDotPath(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+		err    bool
+	}{
+		{input: "", expect: ""},
+		{input: "servers.0.port", expect: "/servers/0/port"},
+		{input: `a\.b.c`, expect: "/a.b/c"},
+		{input: `a\\.b`, expect: `/a\/b`},
+		{input: "a/b~c.*", expect: "/a~1b~0c/*"},
+		{input: "名前.é", expect: "/名前/é"},
+		{input: ".a", err: true},
+		{input: "a.", err: true},
+		{input: "a..b", err: true},
+		{input: `a\b`, err: true},
+		{input: `a\`, err: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.input, func(t *testing.T) {
+			ptr, err := ParseDotPath(tt.input)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, ptr.String())
+		})
+	}
+}
+
+func TestGetPositionsDot(t *testing.T) {
+	input := `{"servers": [{"port": 80}], "a.b": true}`
+	out, err := GetPositionsDot(input, []string{"servers.0.port", `a\.b`})
+	require.NoError(t, err)
+	require.Equal(t, Position{Line: 1, Column: 23, Offset: 22}, out["/servers/0/port"].Position)
+	require.Equal(t, Position{Line: 1, Column: 36, Offset: 35}, out["/a.b"].Position)
+
+	_, err = GetPositionsDot(input, []string{"servers..port"})
+	require.Error(t, err)
+}
@@ -0,0 +1,31 @@
+package jsonpointerpos
+
+import (
+	"os"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// GetPositionsFile is like GetPositions, but takes the document from the file at path.
+// On Linux, macOS and the BSDs, the file is memory-mapped read-only, so that both the decoder and the position
+// resolution operate on the mapped pages, instead of a copy of the whole file in memory. The file must not be truncated
+// by another process meanwhile, which would crash the program on these platforms. On the other platforms, the file is
+// read into memory instead.
+func GetPositionsFile(path string, ptrs []jsonpointer.Pointer, opts ...Option) (map[string]JSONPointerPosition, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	data, unmap, err := mapFile(f, fi.Size())
+	if err != nil {
+		return nil, err
+	}
+	defer unmap()
+	return GetPositionsBytes(data, ptrs, opts...)
+}
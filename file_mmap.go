@@ -0,0 +1,25 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+
+package jsonpointerpos
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// mapFile maps the file of the size into memory read-only, and returns the mapped data with the function to unmap it.
+func mapFile(f *os.File, size int64) ([]byte, func(), error) {
+	// A zero length mapping is invalid
+	if size == 0 {
+		return nil, func() {}, nil
+	}
+	if int64(int(size)) != size {
+		return nil, nil, fmt.Errorf("file size %d is too large to map", size)
+	}
+	data, err := syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() { _ = syscall.Munmap(data) }, nil
+}
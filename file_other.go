@@ -0,0 +1,17 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+package jsonpointerpos
+
+import (
+	"io"
+	"os"
+)
+
+// mapFile reads the whole file into memory, as memory mapping is not supported on this platform.
+func mapFile(f *os.File, size int64) ([]byte, func(), error) {
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, func() {}, nil
+}
@@ -0,0 +1,31 @@
+package jsonpointerpos
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPositionsFile(t *testing.T) {
+	input := "{\n  \"a\": [1, {\"b\": \"é\"}],\n  \"c\": null\n}"
+	path := filepath.Join(t.TempDir(), "doc.json")
+	require.NoError(t, os.WriteFile(path, []byte(input), 0o644))
+
+	ptrs := []jsonpointer.Pointer{mustPointer("/a/1/b"), mustPointer("/c"), mustPointer("/d")}
+	expect, err := GetPositions(input, ptrs, WithRaw())
+	require.NoError(t, err)
+	out, err := GetPositionsFile(path, ptrs, WithRaw())
+	require.NoError(t, err)
+	require.Equal(t, expect, out)
+
+	empty := filepath.Join(t.TempDir(), "empty.json")
+	require.NoError(t, os.WriteFile(empty, nil, 0o644))
+	_, err = GetPositionsFile(empty, ptrs)
+	require.Error(t, err)
+
+	_, err = GetPositionsFile(filepath.Join(t.TempDir(), "nonexist.json"), ptrs)
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
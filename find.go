@@ -0,0 +1,170 @@
+package jsonpointerpos
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// FindByValue returns the positions of all the values within the document that equal the match, in the document
+// order. The match is compared as it's encoded by encoding/json, e.g. nil matches null, and a struct matches the
+// objects with the same members in any order.
+//
+// The numbers are compared by their numeric values instead of their source text, so that 8080, 8080.0 and 8.08e3 are
+// all equal, and so are 0 and -0. The comparison is exact, without being rounded into a float64. With WithJSON5,
+// Infinity and +Infinity are equal, while NaN equals nothing, not even itself. A number never equals a string of the
+// same text.
+//
+// For duplicate object keys, both the value and the position are of the reported occurrence, i.e. the last one unless
+// WithFirstDuplicateKey is specified.
+func FindByValue(document string, match any, opts ...Option) ([]JSONPointerPosition, error) {
+	target, err := normalizeValue(match)
+	if err != nil {
+		return nil, err
+	}
+	values, err := GetPositionsWithValues(document, []jsonpointer.Pointer{{}}, opts...)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := GetAllPositions(document, opts...)
+	if err != nil {
+		return nil, err
+	}
+	var out []JSONPointerPosition
+	var find func(tks []string, v any)
+	find = func(tks []string, v any) {
+		ptr := NewPointer(tks...)
+		if pos, ok := positions[ptr.String()]; ok && equalValues(v, target) {
+			out = append(out, pos)
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			for k, child := range v {
+				find(append(tks[:len(tks):len(tks)], k), child)
+			}
+		case []any:
+			for i, child := range v {
+				find(append(tks[:len(tks):len(tks)], strconv.Itoa(i)), child)
+			}
+		}
+	}
+	find(nil, values[""].Value)
+	sort.Sort(ByPosition(out))
+	return out, nil
+}
+
+// normalizeValue converts the value to the one decoded by decodeValue from its JSON encoding. A json.Number is kept as
+// is, so that the non-finite numbers of JSON5 can be matched.
+func normalizeValue(v any) (any, error) {
+	if n, ok := v.(json.Number); ok {
+		return n, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	return decodeValue(dec, false)
+}
+
+// equalValues reports whether the decoded values are equal, where the numbers are compared by numberKey.
+func equalValues(a, b any) bool {
+	switch a := a.(type) {
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			w, ok := b[k]
+			if !ok || !equalValues(v, w) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		b, ok := b.([]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !equalValues(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case json.Number:
+		b, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		ka, ok := numberKey(string(a))
+		if !ok {
+			return false
+		}
+		kb, ok := numberKey(string(b))
+		return ok && ka == kb
+	default:
+		// The other values are string, bool or nil, which are comparable
+		switch b.(type) {
+		case map[string]any, []any, json.Number:
+			return false
+		}
+		return a == b
+	}
+}
+
+// numberKey returns the canonical text of the number, which is the same for the numbers of the same value, i.e. the
+// significant digits without the leading and trailing zeros, followed by the exponent. The returned bool is false for
+// NaN, which equals no number.
+func numberKey(s string) (string, bool) {
+	sign := ""
+	switch {
+	case strings.HasPrefix(s, "-"):
+		sign, s = "-", s[1:]
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	}
+	switch s {
+	case "Infinity":
+		return sign + s, true
+	case "NaN":
+		return "", false
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		// The hexadecimal integers of JSON5 are compared as the decimal ones
+		n, ok := new(big.Int).SetString(s[2:], 16)
+		if !ok {
+			return sign + s, true
+		}
+		s = n.String()
+	}
+	var exp int64
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		var err error
+		if exp, err = strconv.ParseInt(s[i+1:], 10, 64); err != nil {
+			// The exponent is out of range, so that only the same text is regarded as equal
+			return sign + s, true
+		}
+		s = s[:i]
+	}
+	digits := s
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		digits = s[:i] + s[i+1:]
+		exp -= int64(len(s) - i - 1)
+	}
+	digits = strings.TrimLeft(digits, "0")
+	if digits == "" {
+		// Both 0 and -0 are zero
+		return "0", true
+	}
+	trimmed := strings.TrimRight(digits, "0")
+	exp += int64(len(digits) - len(trimmed))
+	return sign + trimmed + "e" + strconv.FormatInt(exp, 10), true
+}
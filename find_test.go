@@ -0,0 +1,142 @@
+package jsonpointerpos
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindByValue(t *testing.T) {
+	input := `{
+  "port": 8080,
+  "ports": [8080.0, 8.08e3, "8080", 80800e-1, 8081],
+  "host": null,
+  "zero": [0, -0, 0.0e5, 1],
+  "small": [0.1, 1e-1, 10E-2, 0.01],
+  "tags": {"b": [1, true], "a": null},
+  "more": {"a": null, "b": [1, true]}
+}`
+	cases := []struct {
+		name   string
+		match  any
+		opts   []Option
+		expect []string
+	}{
+		{name: "null", match: nil, expect: []string{"/host", "/tags/a", "/more/a"}},
+		{name: "int", match: 8080, expect: []string{"/port", "/ports/0", "/ports/1", "/ports/3"}},
+		{name: "float", match: 8080.0, expect: []string{"/port", "/ports/0", "/ports/1", "/ports/3"}},
+		{name: "number", match: json.Number("8.080e+3"), expect: []string{"/port", "/ports/0", "/ports/1", "/ports/3"}},
+		{name: "string", match: "8080", expect: []string{"/ports/2"}},
+		{name: "zero", match: 0, expect: []string{"/zero/0", "/zero/1", "/zero/2"}},
+		{name: "fraction", match: 0.1, expect: []string{"/small/0", "/small/1", "/small/2"}},
+		{name: "bool", match: true, expect: []string{"/tags/b/1", "/more/b/1"}},
+		{name: "array", match: []any{1, true}, expect: []string{"/tags/b", "/more/b"}},
+		{name: "object", match: map[string]any{"a": nil, "b": []int{1, 1}}, expect: nil},
+		{name: "struct", match: struct {
+			A any   `json:"a"`
+			B []any `json:"b"`
+		}{B: []any{1.0, true}}, expect: []string{"/tags", "/more"}},
+		{name: "none", match: "x", expect: nil},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := FindByValue(input, tt.match, tt.opts...)
+			require.NoError(t, err)
+			var ptrs []string
+			for _, pos := range out {
+				ptrs = append(ptrs, pos.Ptr.String())
+			}
+			require.Equal(t, tt.expect, ptrs)
+		})
+	}
+
+	out, err := FindByValue(input, 8081)
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	require.Equal(t, Position{Line: 3, Column: 47, Offset: 64}, out[0].Position)
+}
+
+func TestFindByValueDuplicateKeys(t *testing.T) {
+	input := `{"a": 1, "a": 2, "b": {"c": 1, "c": 2}}`
+	cases := []struct {
+		match  any
+		opts   []Option
+		expect []Position
+	}{
+		{match: 2, expect: []Position{{Line: 1, Column: 15, Offset: 14}, {Line: 1, Column: 37, Offset: 36}}},
+		{match: 1, expect: nil},
+		{match: map[string]int{"c": 2}, expect: []Position{{Line: 1, Column: 23, Offset: 22}}},
+		{match: 1, opts: []Option{WithFirstDuplicateKey()}, expect: []Position{{Line: 1, Column: 7, Offset: 6}, {Line: 1, Column: 29, Offset: 28}}},
+		{match: map[string]int{"c": 1}, opts: []Option{WithFirstDuplicateKey()}, expect: []Position{{Line: 1, Column: 23, Offset: 22}}},
+	}
+	for _, tt := range cases {
+		out, err := FindByValue(input, tt.match, tt.opts...)
+		require.NoError(t, err)
+		var got []Position
+		for _, pos := range out {
+			got = append(got, pos.Position)
+		}
+		require.Equal(t, tt.expect, got, tt.match)
+	}
+}
+
+func TestFindByValueJSON5(t *testing.T) {
+	input := `[Infinity, +Infinity, -Infinity, NaN, 1.0, 1]`
+	for _, tt := range []struct {
+		match  json.Number
+		expect []string
+	}{
+		{match: "Infinity", expect: []string{"/0", "/1"}},
+		{match: "-Infinity", expect: []string{"/2"}},
+		{match: "NaN", expect: nil},
+		{match: "1", expect: []string{"/4", "/5"}},
+	} {
+		out, err := FindByValue(input, tt.match, WithJSON5())
+		require.NoError(t, err)
+		var ptrs []string
+		for _, pos := range out {
+			ptrs = append(ptrs, pos.Ptr.String())
+		}
+		require.Equal(t, tt.expect, ptrs, tt.match)
+	}
+}
+
+func TestFindByValueError(t *testing.T) {
+	_, err := FindByValue(`[1`, 1)
+	require.Error(t, err)
+
+	// The match that can't be encoded as JSON
+	_, err = FindByValue(`[1]`, math.NaN())
+	require.Error(t, err)
+	_, err = FindByValue(`[1]`, make(chan int))
+	require.Error(t, err)
+}
+
+func TestNumberKey(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+		ok     bool
+	}{
+		{input: "0", expect: "0", ok: true},
+		{input: "-0.000", expect: "0", ok: true},
+		{input: "8080", expect: "808e1", ok: true},
+		{input: "-12.340e5", expect: "-1234e3", ok: true},
+		{input: "0.0012", expect: "12e-4", ok: true},
+		{input: "1E+2", expect: "1e2", ok: true},
+		{input: "+1", expect: "1e0", ok: true},
+		{input: "12345678901234567890123", expect: "12345678901234567890123e0", ok: true},
+		{input: "1e99999999999999999999", expect: "1e99999999999999999999", ok: true},
+		{input: "-Infinity", expect: "-Infinity", ok: true},
+		{input: "-NaN"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.input, func(t *testing.T) {
+			key, ok := numberKey(tt.input)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.expect, key)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+//go:build go1.23
+
+package jsonpointerpos
+
+import (
+	"errors"
+	"iter"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// errStopped is returned by the visit function of GetPositionsSeq to stop the walk, once the iteration is stopped.
+var errStopped = errors.New("iteration is stopped")
+
+// GetPositionsSeq is like WalkPositions, but returns an iterator over the positions of the values that the pointers
+// point to, instead of calling a function. The positions are yielded in document order like WalkPositions, while the
+// document is walked lazily as the iterator advances, and stopping the iteration stops the walk. If the walk fails, the error is yielded last.
+func GetPositionsSeq(document string, ptrs []jsonpointer.Pointer, opts ...Option) iter.Seq2[JSONPointerPosition, error] {
+	return func(yield func(JSONPointerPosition, error) bool) {
+		err := WalkPositions(document, ptrs, func(pos JSONPointerPosition) error {
+			if !yield(pos, nil) {
+				return errStopped
+			}
+			return nil
+		}, opts...)
+		if err != nil && !errors.Is(err, errStopped) {
+			yield(JSONPointerPosition{}, err)
+		}
+	}
+}
+
+// Positions returns an iterator over the positions of the values that the pointers point to in document order, i.e.
+// by their offsets, where an object/array precedes its members. The pointers can contain wildcards, as GetPositions,
+// if the WithWildcards option is passed to Parse.
+func (d *Document) Positions(ptrs []jsonpointer.Pointer) iter.Seq[JSONPointerPosition] {
+	patterns := d.index.opts.patterns(ptrs)
+	return func(yield func(JSONPointerPosition) bool) {
+		for _, pos := range d.sorted {
+			if !matchAny(patterns, pos.Ptr) {
+				continue
+			}
+			if !yield(pos) {
+				return
+			}
+		}
+	}
+}
+
+// matchAny reports whether the pointer is matched by any of the pointers, which might contain wildcards.
+func matchAny(ptrs []jsonpointer.Pointer, ptr jsonpointer.Pointer) bool {
+	for _, p := range ptrs {
+		if hasWildcard(p) {
+			if matchTokens(p.DecodedTokens(), ptr.DecodedTokens()) {
+				return true
+			}
+			continue
+		}
+		if p.String() == ptr.String() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+//go:build go1.23
+
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPositionsSeq(t *testing.T) {
+	input := `{"a": [1, 2, 3], "b": {"c": "x"}, "d": 4}`
+	ptrs := []jsonpointer.Pointer{mustPointer("/d"), mustPointer("/b/c"), mustPointer("/a/*"), mustPointer("/b")}
+	expect, err := GetPositions(input, ptrs, WithWildcards())
+	require.NoError(t, err)
+
+	var got []string
+	for pos, err := range GetPositionsSeq(input, ptrs, WithWildcards()) {
+		require.NoError(t, err)
+		require.Equal(t, expect[pos.Ptr.String()], pos)
+		got = append(got, pos.Ptr.String())
+	}
+	// The positions are yielded in document order, where an object precedes its members
+	require.Equal(t, []string{"/a/0", "/a/1", "/a/2", "/b", "/b/c", "/d"}, got)
+
+	// Stopping the iteration stops the walk
+	got = nil
+	for pos, err := range GetPositionsSeq(input, ptrs, WithWildcards()) {
+		require.NoError(t, err)
+		got = append(got, pos.Ptr.String())
+		if len(got) == 2 {
+			break
+		}
+	}
+	require.Equal(t, []string{"/a/0", "/a/1"}, got)
+
+	// The error is yielded last
+	got = nil
+	var errs []error
+	for pos, err := range GetPositionsSeq(`{"a": [1, 2, }`, ptrs, WithWildcards()) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		got = append(got, pos.Ptr.String())
+	}
+	require.Equal(t, []string{"/a/0", "/a/1"}, got)
+	require.Len(t, errs, 1)
+	var perr *ParseError
+	require.ErrorAs(t, errs[0], &perr)
+}
+
+func TestDocumentPositions(t *testing.T) {
+	input := `{"a": [1, {"c": 2}], "b": {"c": "x"}, "c": 4}`
+	doc, err := Parse(input, WithWildcards())
+	require.NoError(t, err)
+
+	var got []string
+	for pos := range doc.Positions([]jsonpointer.Pointer{mustPointer("/**/c"), mustPointer("/a"), mustPointer("/x")}) {
+		got = append(got, pos.Ptr.String())
+	}
+	require.Equal(t, []string{"/a", "/a/1/c", "/b/c", "/c"}, got)
+
+	got = nil
+	for pos := range doc.Positions([]jsonpointer.Pointer{mustPointer("/**")}) {
+		got = append(got, pos.Ptr.String())
+		if len(got) == 3 {
+			break
+		}
+	}
+	require.Equal(t, []string{"", "/a", "/a/0"}, got)
+}
@@ -0,0 +1,473 @@
+package jsonpointerpos
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+type json5State int
+
+const (
+	// json5Value expects a value, i.e. at the top level, or after a colon or a comma within an array.
+	json5Value json5State = iota
+	// json5ArrayStart expects an element or the end of an array.
+	json5ArrayStart
+	// json5ArrayComma expects a comma or the end of an array.
+	json5ArrayComma
+	// json5ObjectStart expects a key or the end of an object.
+	json5ObjectStart
+	// json5ObjectKey expects a key, after a comma within an object.
+	json5ObjectKey
+	// json5ObjectColon expects the colon after a key.
+	json5ObjectColon
+	// json5ObjectComma expects a comma or the end of an object.
+	json5ObjectComma
+)
+
+// json5Reader is the TokenReader of the JSON5 documents with the WithJSON5 option, which accepts the unquoted object
+// keys, the single-quoted strings, and the numbers of JSON5, e.g. Infinity and 0x1F, in addition to the standard
+// JSON. The comments and the trailing commas are already masked from the input. It has no nesting limit of its own,
+// which is enforced by the walker instead.
+type json5Reader struct {
+	r *bufio.Reader
+	// offset is the offset of the next byte of r.
+	offset int64
+	// start and end are the offsets of the first byte of the last returned token, and of right after it.
+	start, end int64
+	// stack is the opening delimiters of the enclosing objects/arrays.
+	stack []json.Delim
+	state json5State
+}
+
+func newJSON5Reader(r io.Reader) *json5Reader {
+	return &json5Reader{r: bufio.NewReader(r)}
+}
+
+// json5Error is a syntax error of the JSON5 document, whose offset is the offending byte.
+type json5Error struct {
+	msg    string
+	offset int64
+}
+
+func (e *json5Error) Error() string {
+	return e.msg
+}
+
+func (e *json5Error) ErrorOffset() int64 {
+	return e.offset
+}
+
+func (r *json5Reader) Token() (json.Token, error) {
+	for {
+		c, err := r.peek()
+		if err == io.EOF {
+			if len(r.stack) != 0 || r.state != json5Value {
+				return nil, &json5Error{msg: "unexpected end of JSON input", offset: r.offset}
+			}
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case c == ',' && r.state == json5ArrayComma:
+			r.state = json5Value
+			r.read()
+			continue
+		case c == ',' && r.state == json5ObjectComma:
+			r.state = json5ObjectKey
+			r.read()
+			continue
+		case c == ':' && r.state == json5ObjectColon:
+			r.state = json5Value
+			r.read()
+			continue
+		case c == '}' && (r.state == json5ObjectStart || r.state == json5ObjectComma),
+			c == ']' && (r.state == json5ArrayStart || r.state == json5ArrayComma):
+			r.start = r.offset
+			r.read()
+			r.end = r.offset
+			r.stack = r.stack[:len(r.stack)-1]
+			r.afterValue()
+			return json.Delim(c), nil
+		case r.state == json5ObjectStart || r.state == json5ObjectKey:
+			r.start = r.offset
+			key, err := r.readKey(c)
+			if err != nil {
+				return nil, err
+			}
+			r.end = r.offset
+			r.state = json5ObjectColon
+			return key, nil
+		case r.state == json5Value || r.state == json5ArrayStart:
+			r.start = r.offset
+			tk, err := r.readValue(c)
+			if err != nil {
+				return nil, err
+			}
+			r.end = r.offset
+			return tk, nil
+		default:
+			return nil, r.syntaxError(c, "after "+r.context())
+		}
+	}
+}
+
+func (r *json5Reader) More() bool {
+	c, err := r.peek()
+	return err == nil && c != ']' && c != '}'
+}
+
+func (r *json5Reader) InputOffset() int64 {
+	return r.end
+}
+
+// tokenStart implements tokenStarter, as the strings are not always double-quoted.
+func (r *json5Reader) tokenStart() int64 {
+	return r.start
+}
+
+// afterValue updates the state after a value is read.
+func (r *json5Reader) afterValue() {
+	switch {
+	case len(r.stack) == 0:
+		r.state = json5Value
+	case r.stack[len(r.stack)-1] == '[':
+		r.state = json5ArrayComma
+	default:
+		r.state = json5ObjectComma
+	}
+}
+
+// context describes what the current state expects, for the syntax errors.
+func (r *json5Reader) context() string {
+	switch r.state {
+	case json5ArrayComma:
+		return "array element"
+	case json5ObjectColon:
+		return "object key"
+	case json5ObjectComma:
+		return "object key:value pair"
+	default:
+		return "top-level value"
+	}
+}
+
+func (r *json5Reader) syntaxError(c byte, context string) error {
+	return &json5Error{msg: fmt.Sprintf("invalid character %s %s", quoteChar(c), context), offset: r.offset}
+}
+
+// quoteChar formats the character for the syntax errors, in the same way as encoding/json.
+func quoteChar(c byte) string {
+	if c == '\'' {
+		return `'\''`
+	}
+	if c == '"' {
+		return `'"'`
+	}
+	s := strconv.Quote(string(c))
+	return "'" + s[1:len(s)-1] + "'"
+}
+
+// peek skips the whitespaces, and returns the next byte without reading it.
+func (r *json5Reader) peek() (byte, error) {
+	for {
+		b, err := r.r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !isSpace(b[0]) {
+			return b[0], nil
+		}
+		r.read()
+	}
+}
+
+// read reads the next byte, which must have been peeked.
+func (r *json5Reader) read() byte {
+	c, _ := r.r.ReadByte()
+	r.offset++
+	return c
+}
+
+// readKey reads an object key, which is either a string or an identifier.
+func (r *json5Reader) readKey(c byte) (string, error) {
+	if c == '"' || c == '\'' {
+		return r.readString()
+	}
+	ident, err := r.readIdentifier()
+	if err != nil {
+		return "", err
+	}
+	if ident == "" {
+		return "", r.syntaxError(c, "looking for beginning of object key string")
+	}
+	return ident, nil
+}
+
+// readValue reads a value, or the opening delimiter of an object/array.
+func (r *json5Reader) readValue(c byte) (json.Token, error) {
+	switch {
+	case c == '{' || c == '[':
+		r.read()
+		r.stack = append(r.stack, json.Delim(c))
+		if c == '{' {
+			r.state = json5ObjectStart
+		} else {
+			r.state = json5ArrayStart
+		}
+		return json.Delim(c), nil
+	case c == '"' || c == '\'':
+		s, err := r.readString()
+		if err != nil {
+			return nil, err
+		}
+		r.afterValue()
+		return s, nil
+	case c == '-' || c == '+' || c == '.' || c >= '0' && c <= '9':
+		n, err := r.readNumber()
+		if err != nil {
+			return nil, err
+		}
+		r.afterValue()
+		return n, nil
+	}
+	offset := r.offset
+	ident, err := r.readIdentifier()
+	if err != nil {
+		return nil, err
+	}
+	var tk json.Token
+	switch ident {
+	case "true":
+		tk = true
+	case "false":
+		tk = false
+	case "null":
+		tk = nil
+	case "Infinity", "NaN":
+		// The literals are numbers, whose source text is kept as the others
+		tk = json.Number(ident)
+	default:
+		return nil, &json5Error{msg: fmt.Sprintf("invalid character %s looking for beginning of value", quoteChar(c)), offset: offset}
+	}
+	r.afterValue()
+	return tk, nil
+}
+
+// readString reads a double-quoted or single-quoted string, and returns the decoded one.
+func (r *json5Reader) readString() (string, error) {
+	offset := r.offset
+	quote := r.read()
+	raw := []byte{quote}
+	for escaped := false; ; {
+		c, err := r.r.ReadByte()
+		if err == io.EOF {
+			return "", &json5Error{msg: "unexpected end of JSON input", offset: r.offset}
+		}
+		if err != nil {
+			return "", err
+		}
+		r.offset++
+		raw = append(raw, c)
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == quote:
+			s, err := unquoteJSON5(raw)
+			if err != nil {
+				return "", &json5Error{msg: err.Error(), offset: offset}
+			}
+			return s, nil
+		}
+	}
+}
+
+// unquoteJSON5 decodes the quoted string of JSON5, which additionally allows the escapes of ECMAScript, e.g. "\x41",
+// "\'", and the escaped line breaks for the line continuations. The invalid UTF-8 and the unpaired surrogates are
+// replaced by U+FFFD, as encoding/json does.
+func unquoteJSON5(raw []byte) (string, error) {
+	var sb strings.Builder
+	b := raw[1 : len(raw)-1]
+	for i := 0; i < len(b); {
+		c := b[i]
+		if c < 0x20 && c != '\t' {
+			return "", fmt.Errorf("invalid character %s in string literal", quoteChar(c))
+		}
+		if c != '\\' {
+			r, size := utf8.DecodeRune(b[i:])
+			sb.WriteRune(r)
+			i += size
+			continue
+		}
+		i++
+		r, size := utf8.DecodeRune(b[i:])
+		i += size
+		switch r {
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		case '0':
+			if i < len(b) && b[i] >= '0' && b[i] <= '9' {
+				return "", errors.New("invalid octal escape in string literal")
+			}
+			sb.WriteByte(0)
+		case 'x':
+			if i+2 > len(b) {
+				return "", errors.New("invalid hexadecimal escape in string literal")
+			}
+			v, err := strconv.ParseUint(string(b[i:i+2]), 16, 8)
+			if err != nil {
+				return "", errors.New("invalid hexadecimal escape in string literal")
+			}
+			sb.WriteRune(rune(v))
+			i += 2
+		case 'u':
+			v, ok := hex4(b[i:])
+			if !ok {
+				return "", errors.New("invalid unicode escape in string literal")
+			}
+			i += 4
+			// A surrogate pair is escaped as two escapes
+			if utf16.IsSurrogate(v) && i+1 < len(b) && b[i] == '\\' && b[i+1] == 'u' {
+				if w, ok := hex4(b[i+2:]); ok {
+					if dec := utf16.DecodeRune(v, w); dec != utf8.RuneError {
+						v = dec
+						i += 6
+					}
+				}
+			}
+			sb.WriteRune(v)
+		case '\n', '\u2028', '\u2029':
+			// The escaped line break is a line continuation
+		case '\r':
+			// CRLF is a single line break
+			if i < len(b) && b[i] == '\n' {
+				i++
+			}
+		default:
+			if r >= '1' && r <= '9' {
+				return "", errors.New("invalid decimal escape in string literal")
+			}
+			// Any other character escapes itself, e.g. the quotes and the backslash
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String(), nil
+}
+
+// hex4 decodes the 4 hexadecimal digits at the start of b.
+func hex4(b []byte) (rune, bool) {
+	if len(b) < 4 {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(string(b[:4]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(v), true
+}
+
+// readNumber reads a number, and returns its source text. The number can also be Infinity or NaN, optionally with a
+// sign, or any other number of JSON5, see validJSON5Number.
+func (r *json5Reader) readNumber() (json.Number, error) {
+	offset := r.offset
+	raw := []byte{r.read()}
+	if b, _ := r.r.Peek(1); len(b) != 0 && (b[0] == 'I' || b[0] == 'N') && (raw[0] == '-' || raw[0] == '+') {
+		ident, err := r.readIdentifier()
+		if err != nil {
+			return "", err
+		}
+		if ident != "Infinity" && ident != "NaN" {
+			return "", &json5Error{msg: fmt.Sprintf("invalid number literal %q", string(raw)+ident), offset: offset}
+		}
+		return json.Number(string(raw) + ident), nil
+	}
+	for {
+		b, err := r.r.Peek(1)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if len(b) == 0 || !strings.ContainsRune("+-.0123456789xXabcdefABCDEF", rune(b[0])) {
+			break
+		}
+		raw = append(raw, r.read())
+	}
+	if !validJSON5Number(string(raw)) {
+		return "", &json5Error{msg: fmt.Sprintf("invalid number literal %q", raw), offset: offset}
+	}
+	return json.Number(raw), nil
+}
+
+// validJSON5Number reports whether s is a number of JSON5 other than Infinity and NaN, i.e. a JSON number that can also
+// have a leading plus sign, a leading or trailing decimal point, or be hexadecimal, e.g. +1, .5, 5. and 0x1F.
+func validJSON5Number(s string) bool {
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		s = s[1:]
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return len(s) > 2 && strings.Trim(s[2:], "0123456789abcdefABCDEF") == ""
+	}
+	digits := func(s string) bool {
+		return strings.Trim(s, "0123456789") == ""
+	}
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp := strings.TrimLeft(s[i+1:], "+-")
+		if len(s[i+1:])-len(exp) > 1 || exp == "" || !digits(exp) {
+			return false
+		}
+		s = s[:i]
+	}
+	integer, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		integer, fraction = s[:i], s[i+1:]
+	}
+	if integer == "" && fraction == "" || !digits(integer) || !digits(fraction) {
+		return false
+	}
+	// The leading zeros are not allowed as JSON
+	return len(integer) < 2 || integer[0] != '0'
+}
+
+// readIdentifier reads an identifier, which consists of the letters, the digits (except the first one), "_" and "$".
+// It returns an empty string if the next character can't start an identifier.
+func (r *json5Reader) readIdentifier() (string, error) {
+	var sb strings.Builder
+	for {
+		c, size, err := r.r.ReadRune()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if !(c == '_' || c == '$' || unicode.IsLetter(c) || sb.Len() > 0 && unicode.IsDigit(c)) || c == utf8.RuneError && size == 1 {
+			if err := r.r.UnreadRune(); err != nil {
+				return "", err
+			}
+			break
+		}
+		r.offset += int64(size)
+		sb.WriteRune(c)
+	}
+	return sb.String(), nil
+}
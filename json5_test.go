@@ -0,0 +1,250 @@
+package jsonpointerpos
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPositionsJSON5UnquotedKeys(t *testing.T) {
+	input := "{port: 8080, $host_1: {\"name\": \"x\", ключ: [true, null]}, // comment\n  _last: 1,}"
+	expect := map[string]JSONPointerPosition{
+		"/port": {
+			Ptr:            mustPointer("/port"),
+			Depth:          1,
+			Kind:           KindNumber,
+			Position:       Position{Line: 1, Column: 8, Offset: 7},
+			EndPosition:    Position{Line: 1, Column: 11, Offset: 10},
+			ByteLength:     4,
+			KeyPosition:    &Position{Line: 1, Column: 2, Offset: 1},
+			KeyEndPosition: &Position{Line: 1, Column: 5, Offset: 4},
+			ColonPosition:  &Position{Line: 1, Column: 6, Offset: 5},
+			ParentPosition: &Position{Line: 1, Column: 1, Offset: 0},
+			CommaPosition:  &Position{Line: 1, Column: 12, Offset: 11},
+		},
+		"/$host_1/ключ/1": {
+			Ptr:            mustPointer("/$host_1/ключ/1"),
+			Depth:          3,
+			Kind:           KindNull,
+			Position:       Position{Line: 1, Column: 50, Offset: 53},
+			EndPosition:    Position{Line: 1, Column: 53, Offset: 56},
+			ByteLength:     4,
+			ParentPosition: &Position{Line: 1, Column: 43, Offset: 46},
+			CommaPosition:  &Position{Line: 1, Column: 48, Offset: 51},
+		},
+		"/_last": {
+			Ptr:            mustPointer("/_last"),
+			Depth:          1,
+			Kind:           KindNumber,
+			Position:       Position{Line: 2, Column: 10, Offset: 81},
+			EndPosition:    Position{Line: 2, Column: 10, Offset: 81},
+			ByteLength:     1,
+			KeyPosition:    &Position{Line: 2, Column: 3, Offset: 74},
+			KeyEndPosition: &Position{Line: 2, Column: 7, Offset: 78},
+			ColonPosition:  &Position{Line: 2, Column: 8, Offset: 79},
+			ParentPosition: &Position{Line: 1, Column: 1, Offset: 0},
+			CommaPosition:  &Position{Line: 1, Column: 56, Offset: 59},
+		},
+	}
+	var ptrs []jsonpointer.Pointer
+	for k := range expect {
+		ptrs = append(ptrs, mustPointer(k))
+	}
+	for _, r := range []func() io.Reader{
+		func() io.Reader { return strings.NewReader(input) },
+		func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+	} {
+		out, err := GetPositionsReader(r(), ptrs, WithJSON5())
+		require.NoError(t, err)
+		require.Equal(t, expect, out)
+	}
+
+	all, err := GetAllPositions(input, WithJSON5())
+	require.NoError(t, err)
+	require.Equal(t, []string{"", "/$host_1", "/$host_1/name", "/$host_1/ключ", "/$host_1/ключ/0", "/$host_1/ключ/1", "/_last", "/port"}, sortedKeys(all))
+
+	values, err := GetPositionsWithValues(input, []jsonpointer.Pointer{mustPointer("/$host_1")}, WithJSON5())
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"name": "x", "ключ": []any{true, nil}}, values["/$host_1"].Value)
+
+	// The unquoted keys are rejected without the option
+	_, err = GetPositions(input, ptrs)
+	require.Error(t, err)
+}
+
+func TestGetPositionsJSON5Values(t *testing.T) {
+	input := `[1, -2.5e3, "a\"b", true, false, null, {}, []]`
+	std, err := GetAllPositions(input)
+	require.NoError(t, err)
+	json5, err := GetAllPositions(input, WithJSON5())
+	require.NoError(t, err)
+	require.Equal(t, std, json5)
+
+	values, err := GetPositionsWithValues(input, []jsonpointer.Pointer{mustPointer("")}, WithJSON5())
+	require.NoError(t, err)
+	require.Equal(t, []any{json.Number("1"), json.Number("-2.5e3"), `a"b`, true, false, nil, map[string]any{}, []any{}}, values[""].Value)
+}
+
+func TestGetPositionsJSON5Error(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		offset int64
+	}{
+		{name: "key starting with a digit", input: `{1a: 1}`, offset: 1},
+		{name: "missing colon", input: `{a 1}`, offset: 3},
+		{name: "missing comma", input: `[1 2]`, offset: 3},
+		{name: "unknown literal", input: `{a: yes}`, offset: 4},
+		{name: "invalid number", input: `[1.e]`, offset: 1},
+		{name: "leading zero", input: `[01]`, offset: 1},
+		{name: "empty hexadecimal", input: `[0x]`, offset: 1},
+		{name: "decimal point only", input: `[.]`, offset: 1},
+		{name: "double signs", input: `[+-1]`, offset: 1},
+		{name: "mismatched delimiter", input: `{a: 1]`, offset: 5},
+		{name: "unexpected end", input: `{a: [1`, offset: 6},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GetPositions(tt.input, []jsonpointer.Pointer{mustPointer("/x")}, WithJSON5())
+			var perr *ParseError
+			require.ErrorAs(t, err, &perr)
+			require.Equal(t, tt.offset, perr.Position.Offset)
+		})
+	}
+}
+
+func TestGetPositionsJSON5SingleQuotes(t *testing.T) {
+	input := `{'a': 'it\'s "x" // not a comment', "b": '\x41é\
+z', 'c\\': [1]}`
+	ptrs := []jsonpointer.Pointer{mustPointer("/a"), mustPointer("/b"), mustPointer(`/c\`)}
+	for _, r := range []func() io.Reader{
+		func() io.Reader { return strings.NewReader(input) },
+		func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+	} {
+		out, err := GetPositionsReader(r(), ptrs, WithJSON5(), WithRaw())
+		require.NoError(t, err)
+		require.Len(t, out, 3)
+
+		require.Equal(t, Position{Line: 1, Column: 2, Offset: 1}, *out["/a"].KeyPosition)
+		require.Equal(t, Position{Line: 1, Column: 4, Offset: 3}, *out["/a"].KeyEndPosition)
+		require.Equal(t, Position{Line: 1, Column: 7, Offset: 6}, out["/a"].Position)
+		require.Equal(t, `'it\'s "x" // not a comment'`, out["/a"].Raw)
+
+		// The line continuation spans two lines
+		require.Equal(t, Position{Line: 1, Column: 42, Offset: 41}, out["/b"].Position)
+		require.Equal(t, Position{Line: 2, Column: 2, Offset: 51}, out["/b"].EndPosition)
+		require.Equal(t, 11, out["/b"].ByteLength)
+
+		require.Equal(t, Position{Line: 2, Column: 5, Offset: 54}, *out[`/c\`].KeyPosition)
+		require.Equal(t, Position{Line: 2, Column: 9, Offset: 58}, *out[`/c\`].KeyEndPosition)
+		require.Equal(t, "[1]", out[`/c\`].Raw)
+	}
+
+	values, err := GetPositionsWithValues(input, []jsonpointer.Pointer{mustPointer("")}, WithJSON5())
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"a": `it's "x" // not a comment`, "b": "Aéz", `c\`: []any{json.Number("1")}}, values[""].Value)
+}
+
+func TestUnquoteJSON5(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+		err    bool
+	}{
+		{input: `"a\"b\\c\/d"`, expect: `a"b\c/d`},
+		{input: `'\b\f\n\r\t\v\0'`, expect: "\b\f\n\r\t\v\x00"},
+		{input: `'\x41B😀'`, expect: "AB😀"},
+		{input: "'a\\\r\nb\\\nc'", expect: "abc"},
+		{input: `'\q\''`, expect: `q'`},
+		{input: `'\uD83Dx'`, expect: "�x"},
+		{input: "'\xffa'", expect: "�a"},
+		{input: "'a\tb'", expect: "a\tb"},
+		{input: "'a\nb'", err: true},
+		{input: `'\x4'`, err: true},
+		{input: `'\u12'`, err: true},
+		{input: `'\01'`, err: true},
+		{input: `'\1'`, err: true},
+	}
+	for _, tt := range cases {
+		t.Run(tt.input, func(t *testing.T) {
+			s, err := unquoteJSON5([]byte(tt.input))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, s)
+		})
+	}
+}
+
+func TestGetPositionsJSON5Numbers(t *testing.T) {
+	input := `[0x1F, -0XaB, .5, 5., +1, -.5e-3, 5.E+2]`
+	raws := []string{"0x1F", "-0XaB", ".5", "5.", "+1", "-.5e-3", "5.E+2"}
+	out, err := GetPositions(input, []jsonpointer.Pointer{mustPointer("/*")}, WithJSON5(), WithRaw(), WithWildcards())
+	require.NoError(t, err)
+	require.Len(t, out, len(raws))
+	for i, raw := range raws {
+		k := "/" + strconv.Itoa(i)
+		start := int64(strings.Index(input, raw))
+		require.Equal(t, Position{Line: 1, Column: int(start) + 1, Offset: start}, out[k].Position, k)
+		require.Equal(t, KindNumber, out[k].Kind, k)
+		require.Equal(t, raw, out[k].Raw, k)
+	}
+
+	// The numbers are decoded as json.Number of their source text, and compared by their values
+	values, err := GetPositionsWithValues(input, []jsonpointer.Pointer{mustPointer("")}, WithJSON5())
+	require.NoError(t, err)
+	var expect []any
+	for _, raw := range raws {
+		expect = append(expect, json.Number(raw))
+	}
+	require.Equal(t, expect, values[""].Value)
+	for match, ptr := range map[any]string{31: "/0", -171: "/1", 0.5: "/2", 5: "/3", 1: "/4", -0.0005: "/5", 500: "/6"} {
+		found, err := FindByValue(input, match, WithJSON5())
+		require.NoError(t, err)
+		require.Len(t, found, 1, match)
+		require.Equal(t, ptr, found[0].Ptr.String(), match)
+	}
+
+	// They are not accepted without the option
+	_, err = GetPositions(input, []jsonpointer.Pointer{mustPointer("/0")})
+	require.Error(t, err)
+}
+
+func TestGetPositionsJSON5InfinityNaN(t *testing.T) {
+	input := `{"nan": NaN, "inf": [Infinity, -Infinity, +Infinity, -NaN], "x": 1}`
+	ptrs := []jsonpointer.Pointer{mustPointer("/nan"), mustPointer("/inf/*"), mustPointer("/x")}
+	out, err := GetPositions(input, ptrs, WithJSON5(), WithRaw(), WithWildcards())
+	require.NoError(t, err)
+	for k, raw := range map[string]string{"/nan": "NaN", "/inf/0": "Infinity", "/inf/1": "-Infinity", "/inf/2": "+Infinity", "/inf/3": "-NaN", "/x": "1"} {
+		start := int64(strings.Index(input, raw))
+		require.Equal(t, Position{Line: 1, Column: int(start) + 1, Offset: start}, out[k].Position, k)
+		require.Equal(t, len(raw), out[k].ByteLength, k)
+		require.Equal(t, raw, out[k].Raw, k)
+	}
+
+	values, err := GetPositionsWithValues(input, []jsonpointer.Pointer{mustPointer("/inf")}, WithJSON5())
+	require.NoError(t, err)
+	require.Equal(t, []any{json.Number("Infinity"), json.Number("-Infinity"), json.Number("+Infinity"), json.Number("-NaN")}, values["/inf"].Value)
+
+	// The pointer through the literal is reported as traversing a number
+	out, err = GetPositions(input, []jsonpointer.Pointer{mustPointer("/nan/0")}, WithJSON5(), WithReportMissing())
+	require.NoError(t, err)
+	var serr *ErrPointerTraversesScalar
+	require.ErrorAs(t, out["/nan/0"].Err, &serr)
+	require.Equal(t, KindNumber, serr.Kind)
+
+	for _, input := range []string{`[-Inf]`, `[++1]`, `[nan]`} {
+		_, err := GetPositions(input, ptrs, WithJSON5())
+		require.Error(t, err, input)
+	}
+	_, err = GetPositions(input, ptrs)
+	require.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package jsonpointerpos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// GetPositionsJSONPath is like GetPositions, but the values are queried by JSONPath expressions, e.g.
+// "$.servers[*].port". The result is keyed by the JSON pointers of the matched values.
+//
+// Only the following subset of JSONPath is supported:
+//
+//   - The root identifier "$", which must start the expression.
+//   - Child segments in the dot notation (".name") or the bracket notation ("['name']" or `["name"]`).
+//   - Index segments ("[0]"), using non-negative indexes only.
+//   - Wildcards (".*" or "[*]"), which match any object member or array element.
+//   - Recursive descent ("..name", "..*" or "..[0]"), which matches at any depth.
+//
+// Filters, slices, unions and negative indexes are not supported. The wildcards act as such without WithWildcards,
+// while a quoted name of "*" (e.g. "$['*']") is a literal member name. As the expressions are evaluated as JSON
+// pointers, an index also matches the object member named by the same number.
+func GetPositionsJSONPath(document string, exprs []string, opts ...Option) (map[string]JSONPointerPosition, error) {
+	return GetPositionsPaths(document, exprs, JSONPathParser, opts...)
+}
+
+// parseJSONPath converts the JSONPath expression to a JSON pointer, which might contain wildcards.
+func parseJSONPath(expr string) (jsonpointer.Pointer, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return jsonpointer.Pointer{}, fmt.Errorf("invalid JSONPath %q: must start with \"$\"", expr)
+	}
+	var tks []string
+	s := expr[1:]
+	for len(s) != 0 {
+		var (
+			tk  string
+			err error
+		)
+		switch {
+		case strings.HasPrefix(s, ".."):
+			tks = append(tks, recursiveWildcardPattern)
+			s = s[2:]
+			if strings.HasPrefix(s, "[") {
+				tk, s, err = parseJSONPathBracket(s)
+			} else {
+				tk, s, err = parseJSONPathName(s)
+			}
+		case strings.HasPrefix(s, "."):
+			tk, s, err = parseJSONPathName(s[1:])
+		case strings.HasPrefix(s, "["):
+			tk, s, err = parseJSONPathBracket(s)
+		default:
+			err = fmt.Errorf("unexpected %q", s)
+		}
+		if err != nil {
+			return jsonpointer.Pointer{}, fmt.Errorf("invalid JSONPath %q: %v", expr, err)
+		}
+		tks = append(tks, tk)
+	}
+	if len(tks) == 0 {
+		return jsonpointer.Pointer{}, nil
+	}
+	return *newJSONPtr(tks), nil
+}
+
+// parseJSONPathName parses a member name in the dot notation, or a wildcard, from the start of s.
+// It returns the token and the rest of s.
+func parseJSONPathName(s string) (string, string, error) {
+	n := strings.IndexAny(s, ".[")
+	if n == -1 {
+		n = len(s)
+	}
+	if n == 0 {
+		return "", "", fmt.Errorf("missing member name")
+	}
+	if s[:n] == wildcardToken {
+		return wildcardPattern, s[n:], nil
+	}
+	return s[:n], s[n:], nil
+}
+
+// parseJSONPathBracket parses a segment in the bracket notation from the start of s, which is either a quoted member
+// name, an index, or a wildcard. It returns the token and the rest of s.
+func parseJSONPathBracket(s string) (string, string, error) {
+	s = s[1:]
+	if len(s) != 0 && (s[0] == '\'' || s[0] == '"') {
+		quote := s[0]
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			switch s[i] {
+			case '\\':
+				i++
+				if i == len(s) {
+					return "", "", fmt.Errorf("unterminated member name")
+				}
+				sb.WriteByte(s[i])
+			case quote:
+				if !strings.HasPrefix(s[i+1:], "]") {
+					return "", "", fmt.Errorf("missing \"]\" after member name")
+				}
+				return sb.String(), s[i+2:], nil
+			default:
+				sb.WriteByte(s[i])
+			}
+		}
+		return "", "", fmt.Errorf("unterminated member name")
+	}
+
+	n := strings.IndexByte(s, ']')
+	if n == -1 {
+		return "", "", fmt.Errorf("missing \"]\"")
+	}
+	tk := s[:n]
+	if tk == wildcardToken {
+		return wildcardPattern, s[n+1:], nil
+	}
+	if idx, err := strconv.Atoi(tk); err != nil || idx < 0 || strconv.Itoa(idx) != tk {
+		return "", "", fmt.Errorf("unsupported segment %q", "["+tk+"]")
+	}
+	return tk, s[n+1:], nil
+}
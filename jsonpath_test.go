@@ -0,0 +1,104 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect string
+		err    bool
+	}{
+		{
+			input:  "$",
+			expect: "",
+		},
+		{
+			input:  "$.servers[*].port",
+			expect: "/servers/*/port",
+		},
+		{
+			input:  "$['a.b'][\"c\\\"d\"][0]",
+			expect: `/a.b/c"d/0`,
+		},
+		{
+			input:  "$['a/b~c']",
+			expect: "/a~1b~0c",
+		},
+		{
+			input:  "$..name",
+			expect: "/**/name",
+		},
+		{
+			input:  "$..*",
+			expect: "/**/*",
+		},
+		{
+			input:  "$.a..[1]",
+			expect: "/a/**/1",
+		},
+		{
+			input: "a.b",
+			err:   true,
+		},
+		{
+			input: "$.",
+			err:   true,
+		},
+		{
+			input: "$[-1]",
+			err:   true,
+		},
+		{
+			input: "$[0:2]",
+			err:   true,
+		},
+		{
+			input: "$[?(@.a)]",
+			err:   true,
+		},
+		{
+			input: "$['a'",
+			err:   true,
+		},
+		{
+			input: "$a",
+			err:   true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.input, func(t *testing.T) {
+			ptr, err := parseJSONPath(tt.input)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			ptr = literal(ptr)
+			require.Equal(t, tt.expect, ptr.String())
+		})
+	}
+}
+
+func TestGetPositionsJSONPath(t *testing.T) {
+	input := `{"servers": [{"port": 80}, {"port": 443, "name": "b"}], "name": "a"}`
+
+	out, err := GetPositionsJSONPath(input, []string{"$.servers[*].port"})
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	require.Equal(t, int64(22), out["/servers/0/port"].Offset)
+	require.Equal(t, int64(36), out["/servers/1/port"].Offset)
+
+	out, err = GetPositionsJSONPath(input, []string{"$..name"})
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	require.Equal(t, int64(49), out["/servers/1/name"].Offset)
+	require.Equal(t, int64(64), out["/name"].Offset)
+
+	_, err = GetPositionsJSONPath(input, []string{"$.servers[-1]"})
+	require.Error(t, err)
+}
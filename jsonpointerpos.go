@@ -1,321 +1,1868 @@
 package jsonpointerpos
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"sort"
 	"strconv"
 	"strings"
-	"text/scanner"
 
 	"github.com/go-openapi/jsonpointer"
 )
 
 type JSONPointerPosition struct {
-	Ptr jsonpointer.Pointer
+	Ptr jsonpointer.Pointer `json:"pointer"`
+	// Depth is the number of the reference tokens of Ptr, e.g. 0 for the root, and 3 for "/a/b/c", even if it's missing.
+	Depth int `json:"depth"`
+	// Kind is the JSON type of the value. It is KindUnknown for a missing pointer and the "-" array token.
+	Kind Kind `json:"kind,omitempty"`
+	// Position is the position of the first byte of the value itself, e.g. the opening quote of a string, or the
+	// opening delimiter of an object/array, which is its structural start. The whitespaces and the comments before the
+	// value are never included.
 	Position
+	// EndPosition is the position of the last byte of the value, e.g. the closing quote of a string,
+	// the last digit of a number, or the closing delimiter of an object/array.
+	EndPosition Position `json:"endPosition"`
+	// ClosePosition is the position of the closing delimiter, i.e. "}" or "]", if the value is an object/array.
+	// It is nil for the other values.
+	ClosePosition *Position `json:"closePosition,omitempty"`
+	// ByteLength is the length of the value in bytes, including the quotes of a string or the delimiters of an
+	// object/array.
+	ByteLength int `json:"byteLength"`
+	// RuneLength is the length of the value in runes (Unicode code points), including the quotes of a string or the
+	// delimiters of an object/array. It is only set with the WithRuneLength option.
+	RuneLength int `json:"runeLength,omitempty"`
+	// KeyPosition is the position of the opening quote of the key, if the value is an object member.
+	// It is nil for array elements and the root.
+	KeyPosition *Position `json:"keyPosition,omitempty"`
+	// KeyEndPosition is the position of the closing quote of the key (or the last character of an unquoted key with
+	// WithJSON5), if the value is an object member, so that
+	// KeyPosition and KeyEndPosition span the key as in the source, including the escapes. It is nil for array
+	// elements and the root, and is not set by GetPositionsYAML.
+	KeyEndPosition *Position `json:"keyEndPosition,omitempty"`
+	// ColonPosition is the position of the colon between the key and the value, if the value is an object member.
+	// It is nil for array elements and the root.
+	ColonPosition *Position `json:"colonPosition,omitempty"`
+	// ParentPosition is the position of the opening delimiter of the enclosing object/array.
+	// It is nil for the root.
+	ParentPosition *Position `json:"parentPosition,omitempty"`
+	// CommaPosition is the position of the comma that separates the value (or the member, for an object member) from
+	// the preceding one, or from the following one if it's the first one, so that the separator can be removed along
+	// with the value. It is nil if the value is the only one within its parent (unless it has a trailing comma), and
+	// for the root.
+	CommaPosition *Position `json:"commaPosition,omitempty"`
+	// Raw is the source text of the value, including the quotes of a string or the delimiters of an object/array.
+	// It is only set with the WithRaw option.
+	Raw string `json:"raw,omitempty"`
+	// LineText is the source text of the line that the value starts on, without the line break. It is only set with
+	// the WithLineText option.
+	LineText string `json:"lineText,omitempty"`
+	// LeadingComments is the comments right before the value, or before the key if the value is an object member, as
+	// their source text including the "//" or the "/* */". The comments on the same line as the preceding value are
+	// its TrailingComment instead, and a blank line breaks the association, so that only the comments after the last
+	// blank line are included, e.g. none if there is a blank line right before the value. It is only set with the
+	// WithComments option.
+	LeadingComments []string `json:"leadingComments,omitempty"`
+	// TrailingComment is the first comment after the value on the same line, which can follow the comma after the
+	// value. It is only set with the WithComments option, and might be missing from WalkPositions, as the value is
+	// reported before the text after it is read.
+	TrailingComment string `json:"trailingComment,omitempty"`
+	// Missing indicates that the pointer doesn't exist in the document, in which case the positions are all zero.
+	// It is only set with the WithReportMissing option, as the missing pointers are omitted otherwise.
+	Missing bool `json:"missing,omitempty"`
+	// MissingReason tells why the first reference token of the pointer that can't be resolved doesn't exist, e.g.
+	// an array index that is out of range. It is only set along with Missing.
+	MissingReason MissingReason `json:"missingReason,omitempty"`
+	// Err is a *ErrPointerTraversesScalar if the MissingReason is MissingNotContainer, telling which scalar the
+	// pointer can't descend into. It is nil otherwise, and is not set by GetPositionsYAML.
+	Err error `json:"error,omitempty"`
+	// Ancestor is the pointer of the deepest existing value along the pointer, e.g. "/a" for the missing "/a/b/c" if
+	// "/a" exists but "/a/b" doesn't. It is only set along with Missing, with the WithAncestors option.
+	Ancestor *jsonpointer.Pointer `json:"ancestor,omitempty"`
+	// AncestorPosition is the position of the value of the Ancestor. It is set along with the Ancestor.
+	AncestorPosition *Position `json:"ancestorPosition,omitempty"`
+	// InsertPosition is the position of the closing delimiter of the Ancestor, before which the missing member or
+	// element can be inserted, if the Ancestor is an object/array. It is nil if the Ancestor is a scalar value.
+	InsertPosition *Position `json:"insertPosition,omitempty"`
+	// DuplicatePositions is the positions of all the values that the pointer points to in document order, when there
+	// are more than one of them due to duplicate object keys. It is only set with the WithDuplicateKeys option.
+	DuplicatePositions []Position `json:"duplicatePositions,omitempty"`
+}
+
+// String formats the position as the quoted pointer followed by "line:column", or by "missing" if the pointer doesn't
+// exist. It takes over the String method promoted from the embedded Position.
+func (p JSONPointerPosition) String() string {
+	if p.Missing {
+		return strconv.Quote(p.Ptr.String()) + " missing"
+	}
+	return strconv.Quote(p.Ptr.String()) + " " + p.Position.String()
+}
+
+// Kind is the JSON type of a value.
+type Kind int
+
+const (
+	// KindUnknown is the zero value, which is used when there is no value.
+	KindUnknown Kind = iota
+	KindString
+	// KindNumber is the kind of a number, which is a json.Number in the decoded values.
+	KindNumber
+	KindBoolean
+	KindNull
+	KindObject
+	KindArray
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindNumber:
+		return "number"
+	case KindBoolean:
+		return "boolean"
+	case KindNull:
+		return "null"
+	case KindObject:
+		return "object"
+	case KindArray:
+		return "array"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the kind as its String, e.g. "object".
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes the kind from its String, i.e. the inverse of MarshalText.
+func (k *Kind) UnmarshalText(text []byte) error {
+	for v := KindUnknown; v <= KindArray; v++ {
+		if v.String() == string(text) {
+			*k = v
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid kind %q", text)
+}
+
+// MissingReason is the reason why a pointer doesn't exist in the document.
+type MissingReason int
+
+const (
+	// MissingUnknown is the zero value, which is used when the pointer is not missing.
+	MissingUnknown MissingReason = iota
+	// MissingKey means that the object doesn't have the key.
+	MissingKey
+	// MissingIndexOutOfRange means that the array index is not less than the length of the array.
+	MissingIndexOutOfRange
+	// MissingInvalidIndex means that the reference token is not an array index, while the value is an array.
+	MissingInvalidIndex
+	// MissingNotContainer means that the value is neither an object nor an array, e.g. a string.
+	MissingNotContainer
+)
+
+func (r MissingReason) String() string {
+	switch r {
+	case MissingKey:
+		return "key not found"
+	case MissingIndexOutOfRange:
+		return "index out of range"
+	case MissingInvalidIndex:
+		return "invalid array index"
+	case MissingNotContainer:
+		return "not an object or array"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes the reason as its String, e.g. "key not found".
+func (r MissingReason) MarshalText() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalText decodes the reason from its String, i.e. the inverse of MarshalText.
+func (r *MissingReason) UnmarshalText(text []byte) error {
+	for v := MissingUnknown; v <= MissingNotContainer; v++ {
+		if v.String() == string(text) {
+			*r = v
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid missing reason %q", text)
+}
+
+// ErrPointerTraversesScalar is the error of a pointer that descends into a scalar value, e.g. "/a/b" for the document
+// {"a": 5}, which distinguishes a bad pointer from a genuinely absent path. It is set as the Err of the missing pointer
+// with the WithReportMissing option.
+type ErrPointerTraversesScalar struct {
+	// Ptr is the offending pointer.
+	Ptr jsonpointer.Pointer
+	// Scalar is the prefix of Ptr that points to the scalar value.
+	Scalar jsonpointer.Pointer
+	// Kind is the kind of the scalar value, i.e. KindString, KindNumber, KindBoolean or KindNull.
+	Kind Kind
+}
+
+func (e *ErrPointerTraversesScalar) Error() string {
+	return fmt.Sprintf("pointer %q traverses the %s at %q", e.Ptr.String(), e.Kind, e.Scalar.String())
+}
+
+// ErrPointerNotFound is returned with the WithErrorOnMissing option, when any of the pointers doesn't exist in the
+// document.
+type ErrPointerNotFound struct {
+	// Missing is all the pointers that don't exist, in the order they are specified.
+	Missing []jsonpointer.Pointer
+}
+
+func (e *ErrPointerNotFound) Error() string {
+	ptrs := make([]string, len(e.Missing))
+	for i, ptr := range e.Missing {
+		ptrs[i] = strconv.Quote(ptr.String())
+	}
+	return "pointers not found: " + strings.Join(ptrs, ", ")
+}
+
+// indexReason returns the reason why the reference token doesn't exist in an array.
+func indexReason(tk string) MissingReason {
+	if idx, err := strconv.Atoi(tk); err == nil && idx >= 0 && strconv.Itoa(idx) == tk {
+		return MissingIndexOutOfRange
+	}
+	return MissingInvalidIndex
 }
 
+// Position is a position within a JSON document.
+// Any of LF, CRLF and a lone CR is counted as a line break.
 type Position struct {
-	Line   int
-	Column int
+	Line int `json:"line"`
+	// Column is counted in runes (Unicode code points) by default, so that a multibyte UTF-8 character occupies a
+	// single column. See WithUTF16Columns, WithDisplayWidthColumns and WithTabWidth for the alternatives.
+	Column int `json:"column"`
+	// Offset is the byte offset into the document, starting at 0. It is an int64, so that a document streamed by
+	// GetPositionsReader can exceed 2GB even on 32-bit platforms.
+	Offset int64 `json:"offset"`
+}
+
+// String formats the position as "line:column", as is reported with the options (e.g. WithZeroBased and
+// WithBaseOffset).
+func (p Position) String() string {
+	var buf [41]byte
+	b := strconv.AppendInt(buf[:0], int64(p.Line), 10)
+	b = append(b, ':')
+	b = strconv.AppendInt(b, int64(p.Column), 10)
+	return string(b)
+}
+
+// Compare returns -1, 0 or +1 depending on whether p is before, at or after other. The positions are ordered by Line,
+// then Column, then Offset.
+func (p Position) Compare(other Position) int {
+	switch {
+	case p.Line != other.Line:
+		return compareInt64(int64(p.Line), int64(other.Line))
+	case p.Column != other.Column:
+		return compareInt64(int64(p.Column), int64(other.Column))
+	default:
+		return compareInt64(p.Offset, other.Offset)
+	}
+}
+
+// Less reports whether p is before other, as ordered by Compare.
+func (p Position) Less(other Position) bool {
+	return p.Compare(other) < 0
+}
+
+func compareInt64(a, b int64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
 }
 
 func newJSONPtr(tks []string) *jsonpointer.Pointer {
 	if len(tks) == 0 {
 		return nil
 	}
-	encTks := make([]string, len(tks))
-	for i, tk := range tks {
-		encTks[i] = jsonpointer.Escape(tk)
+	encTks := make([]string, len(tks))
+	for i, tk := range tks {
+		encTks[i] = jsonpointer.Escape(tk)
+	}
+	ptr, _ := jsonpointer.New("/" + strings.Join(encTks, "/"))
+	return &ptr
+}
+
+type tokenTree struct {
+	tk string
+	// requested indicates whether this node is pointed to by one of the pointers, rather than an intermediate node.
+	requested bool
+	offset    *int64
+	length    int64
+	// keyOffset is the offset of the key, if this node is an object member.
+	keyOffset *int64
+	// keyEndOffset is the offset of the closing quote of the key, if this node is an object member.
+	keyEndOffset *int64
+	// colonOffset is the offset of the colon between the key and the value, if the node is an object member.
+	colonOffset *int64
+	// parentOffset is the offset of the enclosing object/array, if this node is not the root.
+	parentOffset *int64
+	// commaOffset is the offset of the comma that separates this node from its preceding sibling, or from its
+	// following sibling if it's the first one.
+	commaOffset *int64
+	// delim is the opening delimiter of the value if it's an object/array, or 0 otherwise.
+	delim json.Delim
+	// scalar is the JSON type of the value if it's not an object/array, e.g. KindNumber.
+	scalar Kind
+	// expand indicates to walk all the members/elements of this node as requested nodes, with the WithExpandObjects
+	// option if it's an object, or the WithExpandArrays option if it's an array.
+	expand bool
+	// raw is the source text of the value, only captured for the requested nodes with the WithRaw option.
+	raw string
+	// dupOffsets is the offsets of the values other than the reported one, due to duplicate object keys.
+	// It is only filled in with the WithDuplicateKeys option.
+	dupOffsets []int64
+	children   map[string]*tokenTree
+}
+
+func (tree *tokenTree) add(ptr jsonpointer.Pointer) {
+	tree.addTokens(ptr.DecodedTokens())
+}
+
+// addTokens adds the node of the decoded tokens to the tree. The root is only pointed to by no token, while an empty
+// token (e.g. of "/" or "/a/") is the empty key of an object.
+func (tree *tokenTree) addTokens(tks []string) {
+	if len(tks) == 0 {
+		tree.requested = true
+		return
+	}
+	// A trailing recursive wildcard matches zero level as well
+	if len(tks) == 1 && tks[0] == recursiveWildcardPattern {
+		tree.requested = true
+	}
+	if tree.children == nil {
+		tree.children = map[string]*tokenTree{}
+	}
+	tk := tks[0]
+	subTree, ok := tree.children[tk]
+	if !ok {
+		subTree = &tokenTree{tk: tk}
+		tree.children[tk] = subTree
+	}
+	subTree.addTokens(tks[1:])
+}
+
+// merge merges the children of the src tree into the tree recursively.
+func (tree *tokenTree) merge(src *tokenTree) {
+	tree.requested = tree.requested || src.requested
+	tree.expand = tree.expand || src.expand
+	for tk, srcChild := range src.children {
+		if tree.children == nil {
+			tree.children = map[string]*tokenTree{}
+		}
+		child, ok := tree.children[tk]
+		if !ok {
+			child = &tokenTree{tk: tk}
+			tree.children[tk] = child
+		}
+		child.merge(srcChild)
+	}
+}
+
+// flatten flattens the token tree to a map whose key is a json pointer and its value is the tree node.
+// For token tree nodes that have no offset (implies they doesn't exist in the json document), they are skipped.
+func (tree *tokenTree) flatten() map[string]*tokenTree {
+	out := map[string]*tokenTree{}
+	tree.flattenInto(out, "")
+	return out
+}
+
+// flattenInto adds the tree node and its descendants to out, where key is the json pointer of the tree node, which is
+// empty for the root node. The key of each node is built once from the one of its parent, so that a deeply nested
+// document isn't copied level by level.
+func (tree *tokenTree) flattenInto(out map[string]*tokenTree, key string) {
+	for _, child := range tree.children {
+		child.flattenInto(out, key+"/"+jsonpointer.Escape(child.tk))
+	}
+	if tree.offset != nil {
+		out[key] = tree
+	}
+}
+
+// reset unsets the offsets of the tree and its descendants in place, so that the later occurrence of a duplicate key
+// is reported instead. The offsets of the values are kept in their dupOffsets if keep is true.
+func (tree *tokenTree) reset(keep bool) {
+	for _, child := range tree.children {
+		child.reset(keep)
+	}
+	if tree.offset == nil {
+		return
+	}
+	dupOffsets := tree.dupOffsets
+	if keep {
+		dupOffsets = append(dupOffsets, *tree.offset)
+	}
+	*tree = tokenTree{tk: tree.tk, requested: tree.requested, expand: tree.expand, children: tree.children, dupOffsets: dupOffsets}
+}
+
+// clone returns a deep copy of the tree, without any offset filled in.
+func (tree *tokenTree) clone() *tokenTree {
+	out := &tokenTree{tk: tree.tk, requested: tree.requested}
+	if tree.children != nil {
+		out.children = make(map[string]*tokenTree, len(tree.children))
+		for tk, child := range tree.children {
+			out.children[tk] = child.clone()
+		}
+	}
+	return out
+}
+
+// find returns the tree node of the pointer, or nil if it doesn't exist.
+func (tree *tokenTree) find(ptr jsonpointer.Pointer) *tokenTree {
+	node := tree
+	for _, tk := range ptr.DecodedTokens() {
+		node = node.children[tk]
+		if node == nil {
+			return nil
+		}
+	}
+	return node
+}
+
+// missingReason returns the reason why the pointer is missing, by finding the deepest walked value along the pointer.
+// As the requested subtrees are walked fully, the next reference token doesn't exist in that value. The error is
+// returned along with MissingNotContainer.
+func (tree *tokenTree) missingReason(ptr jsonpointer.Pointer) (MissingReason, error) {
+	tks := ptr.DecodedTokens()
+	node, n := tree.deepest(tks)
+	if n == len(tks) || node.offset == nil {
+		return MissingUnknown, nil
+	}
+	switch node.delim {
+	case '{':
+		return MissingKey, nil
+	case '[':
+		return indexReason(tks[n]), nil
+	default:
+		return MissingNotContainer, &ErrPointerTraversesScalar{Ptr: ptr, Scalar: NewPointer(tks[:n]...), Kind: node.scalar}
+	}
+}
+
+// deepest returns the deepest walked node along the decoded tokens, and the number of the tokens leading to it.
+func (tree *tokenTree) deepest(tks []string) (*tokenTree, int) {
+	node := tree
+	for i, tk := range tks {
+		child := node.children[tk]
+		if child == nil || child.offset == nil {
+			return node, i
+		}
+		node = child
+	}
+	return node, len(tks)
+}
+
+// kind returns the JSON type of the value of the node.
+func (tree *tokenTree) kind() Kind {
+	switch tree.delim {
+	case '{':
+		return KindObject
+	case '[':
+		return KindArray
+	default:
+		return tree.scalar
+	}
+}
+
+func buildTokenTree(ptrs []jsonpointer.Pointer) tokenTree {
+	root := tokenTree{}
+	for _, ptr := range ptrs {
+		root.add(ptr)
+	}
+	return root
+}
+
+// GetPositions returns the positions of the values that the specified JSON pointers point to within the document.
+// The pointers that don't exist in the document are omitted from the result. The empty pointer "" points to the
+// root value. If an object has duplicate keys, the value of the last occurrence is reported as encoding/json
+// decodes, unless WithFirstDuplicateKey is specified, and all the occurrences are reported with WithDuplicateKeys.
+// The pointers in the URI fragment representation can be parsed by
+// ParsePointer.
+//
+// The reference tokens "*" and "**" are literal keys as in RFC 6901, unless WithWildcards is specified, with which
+// they act as wildcards that are expanded to the matched pointers in the result.
+//
+// A reference token of "-" within an array refers to the nonexistent element after the last one, as used for
+// appending. It is resolved to the insertion point rather than an existing value: the offset right after the last
+// element, or right after the opening "[" of an empty array, with a ByteLength of 0.
+func GetPositions(document string, ptrs []jsonpointer.Pointer, opts ...Option) (map[string]JSONPointerPosition, error) {
+	return GetPositionsReader(strings.NewReader(document), ptrs, opts...)
+}
+
+// GetPosition returns the position of the value that the specified JSON pointer points to within the document.
+// The returned bool reports whether the pointer exists in the document.
+func GetPosition(document string, ptr jsonpointer.Pointer, opts ...Option) (JSONPointerPosition, bool, error) {
+	m, err := GetPositions(document, []jsonpointer.Pointer{ptr}, opts...)
+	if err != nil {
+		return JSONPointerPosition{}, false, err
+	}
+	pos, ok := m[ptr.String()]
+	return pos, ok && !pos.Missing, nil
+}
+
+// GetPositionsSorted is like GetPositions, but returns the positions as a slice sorted by the document order, i.e.
+// by line and then by column.
+func GetPositionsSorted(document string, ptrs []jsonpointer.Pointer, opts ...Option) ([]JSONPointerPosition, error) {
+	m, err := GetPositions(document, ptrs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]JSONPointerPosition, 0, len(m))
+	for _, pos := range m {
+		out = append(out, pos)
+	}
+	sort.Sort(ByPosition(out))
+	return out, nil
+}
+
+// ByPosition implements sort.Interface over the positions, ordered by ComparePositions.
+type ByPosition []JSONPointerPosition
+
+func (s ByPosition) Len() int           { return len(s) }
+func (s ByPosition) Less(i, j int) bool { return ComparePositions(s[i], s[j]) < 0 }
+func (s ByPosition) Swap(i, j int)      { s[i], s[j] = s[j], s[i] }
+
+// ComparePositions returns -1, 0 or +1 depending on whether a is before, at or after b in the document order, i.e. by
+// line and then by column, and by the pointer at last (e.g. for the missing pointers). It can be used as the comparator
+// of slices.SortFunc.
+func ComparePositions(a, b JSONPointerPosition) int {
+	if a.Line != b.Line || a.Column != b.Column {
+		return a.Position.Compare(b.Position)
+	}
+	return strings.Compare(a.Ptr.String(), b.Ptr.String())
+}
+
+// GetPositionsContext is like GetPositions, but aborts with the context's error once the context is done.
+func GetPositionsContext(ctx context.Context, document string, ptrs []jsonpointer.Pointer, opts ...Option) (map[string]JSONPointerPosition, error) {
+	w := newWalker(strings.NewReader(document), newOptions(opts))
+	w.ctx = ctx
+	return getPositions(w, ptrs)
+}
+
+// GetPositionsReader is like GetPositions, but reads the document from r.
+// The positions are resolved incrementally during decoding, so that the memory usage is bounded by the number of
+// the found positions, instead of the size of the document.
+// The reader is read until its end to make sure the rest of the document is valid and nothing follows the top-level
+// value, unless WithAllowTrailing is specified, in which case it is only read until the end of the top-level value, or
+// until all the pointers are found when there is no wildcard, though as the decoder buffers its input, some data
+// beyond that might also be read.
+func GetPositionsReader(r io.Reader, ptrs []jsonpointer.Pointer, opts ...Option) (map[string]JSONPointerPosition, error) {
+	o := newOptions(opts)
+	if o.recover {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return GetPositionsBytes(data, ptrs, opts...)
+	}
+	return getPositions(newWalker(r, o), ptrs)
+}
+
+// GetPositionsBytes is like GetPositions, but takes the document as a byte slice, which avoids the conversion to string.
+func GetPositionsBytes(data []byte, ptrs []jsonpointer.Pointer, opts ...Option) (map[string]JSONPointerPosition, error) {
+	o := newOptions(opts)
+	if o.recover {
+		return recoverPositions(data, o, func(w *walker) (map[string]JSONPointerPosition, error) {
+			return getPositions(w, ptrs)
+		})
+	}
+	return getPositions(newBytesWalker(data, o), ptrs)
+}
+
+// GetPositionsRaw is like GetPositionsBytes, but takes a json.RawMessage, e.g. a field that is decoded later. The
+// positions are relative to the raw message itself, see WithBaseOffset for those within the enclosing document.
+func GetPositionsRaw(data json.RawMessage, ptrs []jsonpointer.Pointer, opts ...Option) (map[string]JSONPointerPosition, error) {
+	return GetPositionsBytes(data, ptrs, opts...)
+}
+
+func getPositions(w *walker, ptrs []jsonpointer.Pointer) (map[string]JSONPointerPosition, error) {
+	if len(ptrs) == 0 {
+		return nil, nil
+	}
+	ptrs = w.pos.opts.patterns(ptrs)
+	tree := buildTokenTree(ptrs)
+	return w.positions(&tree, ptrs)
+}
+
+// positions walks through the document with the token tree built from the pointers, and returns their positions.
+func (w *walker) positions(tree *tokenTree, ptrs []jsonpointer.Pointer) (map[string]JSONPointerPosition, error) {
+	if w.pos.opts.expandObjects || w.pos.opts.expandArrays {
+		for _, ptr := range ptrs {
+			if node := tree.find(ptr); node != nil {
+				node.expand = true
+			}
+		}
+	}
+	w.setPending(tree, ptrs)
+	err := w.walk(tree)
+	truncated := w.truncated(err)
+	if err != nil && truncated == nil {
+		return nil, err
+	}
+	if w.skipped != nil {
+		tree.prune(w.skipped)
+	}
+
+	m := tree.flatten()
+	nm := map[string]*tokenTree{}
+	matched := map[string]bool{}
+	// Only keep the specified pointers from the flattened map
+	for _, ptr := range ptrs {
+		if !hasWildcard(ptr) {
+			if v, ok := m[ptr.String()]; ok {
+				nm[ptr.String()] = v
+				w.expandMembers(nm, m, ptr.String(), v)
+			}
+			continue
+		}
+		for k := range m {
+			if matchWildcard(ptr, k) {
+				matched[k] = true
+			}
+		}
+	}
+	for _, k := range w.firstMatches(m, matched) {
+		nm[k] = m[k]
+		w.expandMembers(nm, m, k, m[k])
+	}
+	out, err := w.jsonPointerPositions(nm)
+	if err != nil {
+		return nil, err
+	}
+	if w.pos.opts.errorOnMissing && truncated == nil {
+		if err := notFound(out, ptrs); err != nil {
+			return nil, err
+		}
+	}
+	if w.pos.opts.reportMissing {
+		for _, ptr := range ptrs {
+			if _, ok := out[ptr.String()]; !ok && !hasWildcard(ptr) {
+				reason, err := tree.missingReason(ptr)
+				pos := JSONPointerPosition{Ptr: ptr, Depth: len(ptr.DecodedTokens()), Missing: true, MissingReason: reason, Err: err}
+				if w.pos.opts.ancestors {
+					w.ancestor(&pos, tree)
+				}
+				out[ptr.String()] = pos
+			}
+		}
+	}
+	if truncated != nil {
+		return out, truncated
+	}
+	return out, nil
+}
+
+// firstMatches returns the pointers of the wildcard matches, which are limited to the first ones in document order
+// with the WithMaxMatches option.
+func (w *walker) firstMatches(m map[string]*tokenTree, matched map[string]bool) []string {
+	out := make([]string, 0, len(matched))
+	for k := range matched {
+		out = append(out, k)
+	}
+	n := w.pos.opts.maxMatches
+	if n <= 0 || len(out) <= n {
+		return out
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if a, b := *m[out[i]].offset, *m[out[j]].offset; a != b {
+			return a < b
+		}
+		return out[i] < out[j]
+	})
+	return out[:n]
+}
+
+// notFound returns an *ErrPointerNotFound if any of the pointers without wildcards is not in the result.
+func notFound(out map[string]JSONPointerPosition, ptrs []jsonpointer.Pointer) error {
+	var missing []jsonpointer.Pointer
+	seen := map[string]bool{}
+	for _, ptr := range ptrs {
+		if _, ok := out[ptr.String()]; ok || hasWildcard(ptr) || seen[ptr.String()] {
+			continue
+		}
+		seen[ptr.String()] = true
+		missing = append(missing, ptr)
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	return &ErrPointerNotFound{Missing: missing}
+}
+
+// ancestor sets the Ancestor of the missing pointer, along with its InsertPosition if requested.
+func (w *walker) ancestor(pos *JSONPointerPosition, tree *tokenTree) {
+	tks := pos.Ptr.DecodedTokens()
+	node, n := tree.deepest(tks)
+	// The root value of a truncated document isn't complete
+	if node.offset == nil {
+		return
+	}
+	ancestor := NewPointer(tks[:n]...)
+	pos.Ancestor = &ancestor
+	ancestorPos := w.pos.positions[*node.offset]
+	pos.AncestorPosition = &ancestorPos
+	if w.pos.opts.insertPositions && node.delim != 0 {
+		// The closing delimiter is the last byte of the object/array
+		insertPos := w.pos.positions[*node.offset+node.length-1]
+		pos.InsertPosition = &insertPos
+	}
+}
+
+// expandMembers adds the members/elements of the node from the flattened map to nm, if the node is an object/array to
+// be expanded.
+func (w *walker) expandMembers(nm, m map[string]*tokenTree, ptrStr string, node *tokenTree) {
+	if !w.pos.opts.expands(node) {
+		return
+	}
+	for tk := range node.children {
+		k := ptrStr + "/" + jsonpointer.Escape(tk)
+		if v, ok := m[k]; ok {
+			nm[k] = v
+		}
+	}
+}
+
+// setPending sets the tree nodes that the pointers point to as pending, so that the walk stops once they are all found.
+func (w *walker) setPending(tree *tokenTree, ptrs []jsonpointer.Pointer) {
+	// Stop walking once all the pointers are found, unless there are wildcards, which match an unknown number of values,
+	// or the duplicate keys are to be found or rejected, or the whole value is to be consumed, or the comments after the
+	// values are to be read. With the WithMaxMatches option, the wildcard matches are pending once they begin instead,
+	// until the limit is reached.
+	w.pending = map[*tokenTree]bool{}
+	if w.duplicateKeys || w.exhaustive || w.strictKeys || w.comments != nil {
+		w.pending = nil
+	}
+	w.limitMatches(tree, ptrs)
+	for _, ptr := range ptrs {
+		if hasWildcard(ptr) {
+			if w.wildcards == nil {
+				w.pending = nil
+			}
+			continue
+		}
+		if node := tree.find(ptr); node != nil && w.pending != nil {
+			w.pending[node] = true
+		}
+	}
+}
+
+// limitMatches sets up the walk to limit the wildcard matches with the WithMaxMatches option, if any pointer has
+// wildcards. The root is counted first if it's matched, e.g. by "/**".
+func (w *walker) limitMatches(tree *tokenTree, ptrs []jsonpointer.Pointer) {
+	w.wildcards, w.explicit, w.matches = nil, nil, 0
+	if w.pos.opts.maxMatches <= 0 {
+		return
+	}
+	for _, ptr := range ptrs {
+		if hasWildcard(ptr) {
+			w.wildcards = append(w.wildcards, ptr.DecodedTokens())
+		} else {
+			w.explicit = append(w.explicit, ptr.DecodedTokens())
+		}
+	}
+	if w.wildcards == nil {
+		return
+	}
+	w.matches = w.pos.opts.maxMatches
+	w.countMatch(tree, nil)
+}
+
+// countMatch counts the tree node of the path as a wildcard match if it is, and adds it as pending. It reports
+// whether the node is to be walked, which is false once the limit is reached, unless it leads to a pointer without
+// wildcards.
+func (w *walker) countMatch(tree *tokenTree, path []string) bool {
+	if w.matches == 0 {
+		for _, tks := range w.explicit {
+			if len(tks) >= len(path) && equalTokens(tks[:len(path)], path) {
+				return true
+			}
+		}
+		return false
+	}
+	// The value already walked within a duplicate object key is found already
+	if tree.offset != nil {
+		return true
+	}
+	for _, tks := range w.wildcards {
+		if matchTokens(tks, path) {
+			w.matches--
+			if w.pending != nil {
+				w.pending[tree] = true
+			}
+			break
+		}
+	}
+	return true
+}
+
+func equalTokens(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return len(a) == len(b)
+}
+
+// GetAllPositions returns the positions of all the values within the document, keyed by their JSON pointers.
+// Both the scalar values and the containers (i.e. objects and arrays) are included, as well as the root value, whose
+// pointer is "".
+func GetAllPositions(document string, opts ...Option) (map[string]JSONPointerPosition, error) {
+	o := newOptions(opts)
+	if o.recover {
+		return recoverPositions([]byte(document), o, (*walker).allPositions)
+	}
+	return newWalker(strings.NewReader(document), o).allPositions()
+}
+
+// allPositions walks through the whole document, and returns the positions of all the values.
+func (w *walker) allPositions() (map[string]JSONPointerPosition, error) {
+	w.all = true
+	tree := tokenTree{}
+	err := w.walk(&tree)
+	truncated := w.truncated(err)
+	if err != nil && truncated == nil {
+		return nil, err
+	}
+	if w.skipped != nil {
+		tree.prune(w.skipped)
+	}
+	out, err := w.jsonPointerPositions(tree.flatten())
+	if err != nil {
+		return nil, err
+	}
+	if truncated != nil {
+		return out, truncated
+	}
+	return out, nil
+}
+
+// jsonPointerPositions converts the flattened token tree nodes to their positions.
+func (w *walker) jsonPointerPositions(m map[string]*tokenTree) (map[string]JSONPointerPosition, error) {
+	out := map[string]JSONPointerPosition{}
+	for ptrStr, node := range m {
+		ptr, err := jsonpointer.New(ptrStr)
+		if err != nil {
+			return nil, err
+		}
+		out[ptrStr] = w.jsonPointerPosition(ptr, node)
+	}
+	return out, nil
+}
+
+// jsonPointerPosition converts the token tree node that the pointer points to to its position.
+func (w *walker) jsonPointerPosition(ptr jsonpointer.Pointer, node *tokenTree) JSONPointerPosition {
+	positions := w.pos.positions
+	pos := JSONPointerPosition{
+		Ptr:         ptr,
+		Depth:       len(ptr.DecodedTokens()),
+		Kind:        node.kind(),
+		Position:    positions[*node.offset],
+		EndPosition: positions[*node.offset+node.length-1],
+		ByteLength:  int(node.length),
+		Raw:         node.raw,
+	}
+	// The insertion point of the "-" token has no value
+	if node.length == 0 {
+		pos.EndPosition = pos.Position
+	} else if w.pos.runeIndexes != nil {
+		pos.RuneLength = int(w.pos.runeIndexes[*node.offset+node.length-1] - w.pos.runeIndexes[*node.offset] + 1)
+	}
+	if w.pos.lineStarts != nil {
+		pos.LineText = w.pos.lineText(*node.offset)
+	}
+	if w.comments != nil && node.length != 0 {
+		start := *node.offset
+		if node.keyOffset != nil {
+			start = *node.keyOffset
+		}
+		pos.LeadingComments = w.comments.leading(start)
+		pos.TrailingComment = w.comments.trailing(*node.offset + node.length - 1)
+	}
+	if node.delim != 0 {
+		closePos := pos.EndPosition
+		pos.ClosePosition = &closePos
+	}
+	if node.keyOffset != nil {
+		keyPos := positions[*node.keyOffset]
+		pos.KeyPosition = &keyPos
+		keyEndPos := positions[*node.keyEndOffset]
+		pos.KeyEndPosition = &keyEndPos
+	}
+	if node.colonOffset != nil {
+		colonPos := positions[*node.colonOffset]
+		pos.ColonPosition = &colonPos
+	}
+	if node.parentOffset != nil {
+		parentPos := positions[*node.parentOffset]
+		pos.ParentPosition = &parentPos
+	}
+	if node.commaOffset != nil {
+		commaPos := positions[*node.commaOffset]
+		pos.CommaPosition = &commaPos
+	}
+	if len(node.dupOffsets) != 0 {
+		offsets := append([]int64{*node.offset}, node.dupOffsets...)
+		sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+		for _, offset := range offsets {
+			pos.DuplicatePositions = append(pos.DuplicatePositions, positions[offset])
+		}
+	}
+	return pos
+}
+
+// walker walks through a JSON document with a decoder, and resolves the positions of the offsets it fills in the
+// token tree along the way.
+type walker struct {
+	dec TokenReader
+	pos *positioner
+	// raw indicates to capture the raw text of the requested nodes.
+	raw bool
+	// duplicateKeys indicates to walk through the values of the duplicate object keys, instead of only the reported one.
+	duplicateKeys bool
+	// strictKeys indicates to walk through the whole document, and to return an error on any duplicate object key.
+	strictKeys bool
+	// ctx, if not nil, is checked periodically for cancellation during walking.
+	ctx    context.Context
+	tokens int
+	// pending, if not nil, is the set of tree nodes to be found, the walk stops once they are all found.
+	pending map[*tokenTree]bool
+	// objects is the number of the objects being walked. deferred is the pending nodes found within them, which are
+	// only regarded as found once they all end, as the key of the last occurrence is reported.
+	objects  int
+	deferred []*tokenTree
+	// wildcards is the decoded tokens of the pointers with wildcards, and explicit is those of the others, which are
+	// only set with the WithMaxMatches option. matches is the number of the wildcard matches that can still be walked.
+	wildcards, explicit [][]string
+	matches             int
+	// all indicates to walk through all the values, by adding them to the token tree on the fly.
+	all bool
+	// exhaustive indicates to consume the whole value even if all the pointers are found, so that the decoder can
+	// continue with the next value.
+	exhaustive bool
+	// visit, if not nil, is called with the position of each requested tree node in the order of their beginnings,
+	// once it and the ones that begin before it are found. visits is the queue of the begun nodes to be visited.
+	visit  func(JSONPointerPosition) error
+	visits []visitEntry
+	// begin, if not nil, is called with each value once it begins, whose pointer is the current path. The values are
+	// not kept in the token tree then, whose positions are dropped once reported.
+	begin func(jsonpointer.Pointer, Kind, Position) error
+	// path is the decoded tokens of the value being walked.
+	path []string
+	// depth is the nesting depth of the objects/arrays being walked, which is limited by maxDepth if positive, or
+	// defaultMaxDepth otherwise.
+	depth    int
+	maxDepth int
+	// skipped, if not nil, is the end offsets of the malformed objects/arrays that are masked by recoverPositions.
+	skipped map[int64]bool
+	// comments, if not nil, records the comments of the document with the WithComments option.
+	comments *commentList
+}
+
+func newWalker(r io.Reader, opts options) *walker {
+	pos := newPositioner(r, opts)
+	comments := newWalkerComments(opts)
+	dec := newTokenReader(pos, opts, comments)
+	pos.sync = func() int64 {
+		return dec.InputOffset()
+	}
+	return &walker{
+		dec: dec,
+		pos: pos,
+		raw: opts.raw,
+
+		duplicateKeys: opts.duplicateKeys,
+		strictKeys:    opts.strictDuplicateKeys,
+		maxDepth:      opts.maxDepth,
+		comments:      comments,
+	}
+}
+
+// newBytesWalker is like newWalker, but the positioner scans the data directly, instead of a copy of it.
+func newBytesWalker(data []byte, opts options) *walker {
+	pos := newPositioner(nil, opts)
+	pos.buf = data
+	comments := newWalkerComments(opts)
+	return &walker{
+		dec: newTokenReader(bytes.NewReader(data), opts, comments),
+		pos: pos,
+		raw: opts.raw,
+
+		duplicateKeys: opts.duplicateKeys,
+		strictKeys:    opts.strictDuplicateKeys,
+		maxDepth:      opts.maxDepth,
+		comments:      comments,
+	}
+}
+
+// newWalkerComments returns the list to record the comments into with the WithComments option, or nil otherwise.
+func newWalkerComments(opts options) *commentList {
+	if !opts.comments {
+		return nil
+	}
+	return newCommentList()
+}
+
+// TokenReader reads the tokens of a JSON document, which the document is walked with. It is implemented by
+// *json.Decoder, which is used by default, and another implementation can be plugged in by WithTokenReader.
+type TokenReader interface {
+	// Token returns the next token in the same way as *json.Decoder with UseNumber, i.e. a json.Delim for the
+	// delimiters of objects/arrays, a bool, a json.Number, a string, or nil for null. It returns io.EOF at the end of
+	// the input. A syntax error should either be a *json.SyntaxError, or have an ErrorOffset() int64 method that returns
+	// the offset of the offending byte, so that it's reported as a ParseError at the right position.
+	Token() (json.Token, error)
+	// More reports whether there is another member/element in the current object/array.
+	More() bool
+	// InputOffset returns the byte offset of the input right after the last returned token.
+	InputOffset() int64
+}
+
+// newTokenReader returns the TokenReader that reads the document from r.
+// The masked comments are recorded into list, if it's not nil.
+func newTokenReader(r io.Reader, opts options, list *commentList) TokenReader {
+	if opts.tokenReader != nil {
+		return opts.tokenReader(maskReader(r, opts, list))
+	}
+	if opts.json5 {
+		return newJSON5Reader(maskReader(r, opts, list))
+	}
+	return newDecoder(r, opts, list)
+}
+
+func newDecoder(r io.Reader, opts options, list *commentList) *json.Decoder {
+	dec := json.NewDecoder(maskReader(r, opts, list))
+	dec.UseNumber()
+	return dec
+}
+
+// maskReader masks the content that is not valid JSON but allowed by the options with whitespaces, so that the
+// offsets are kept. The masked comments are recorded into list, if it's not nil.
+func maskReader(r io.Reader, opts options, list *commentList) io.Reader {
+	r = &bomMasker{r: r}
+	if opts.comments || opts.trailingCommas {
+		r = newMasker(r, opts, list)
+	}
+	return r
+}
+
+// ctxCheckInterval is the number of tokens read between two checks of the context.
+const ctxCheckInterval = 1024
+
+// token reads the next token from the decoder, and checks the cancellation of the context periodically.
+func (w *walker) token() (json.Token, error) {
+	if w.ctx != nil {
+		if w.tokens%ctxCheckInterval == 0 {
+			if err := w.ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		w.tokens++
+	}
+	tk, err := w.dec.Token()
+	if err != nil {
+		return nil, w.parseError(err)
+	}
+	return tk, nil
+}
+
+// parseError wraps the error returned by the decoder as a *ParseError. The offset is the byte that the syntax error
+// is detected at, or the end of the read bytes otherwise (e.g. for an unexpected EOF).
+func (w *walker) parseError(err error) error {
+	offset := w.pos.offset + int64(len(w.pos.buf))
+	var serr *json.SyntaxError
+	var oerr interface{ ErrorOffset() int64 }
+	switch {
+	// The syntax error offset is right after the offending byte, except for the end of input
+	case errors.As(err, &serr) && serr.Error() != "unexpected end of JSON input" && serr.Offset > 0 && serr.Offset-1 < offset:
+		offset = serr.Offset - 1
+	case errors.As(err, &oerr) && oerr.ErrorOffset() < offset:
+		offset = oerr.ErrorOffset()
+	}
+	if offset < w.pos.offset {
+		offset = w.pos.offset
+	}
+	return &ParseError{Position: w.pos.position(offset), Err: err}
+}
+
+// truncated returns a *ParseError of ErrTruncated with the WithPartial option, if the walk fails with the error due to
+// the end of a truncated document. Otherwise, it returns nil.
+func (w *walker) truncated(err error) error {
+	var perr *ParseError
+	if !w.pos.opts.partial || !errors.As(err, &perr) || errors.Is(err, ErrTrailingContent) {
+		return nil
+	}
+	// The error of a truncated document is detected at the end, which differs among the token readers
+	end := w.pos.offset + int64(len(w.pos.buf))
+	if w.pos.r != nil && !w.pos.eof || perr.Position.Offset != end+w.pos.opts.baseOffset() {
+		return nil
 	}
-	ptr, _ := jsonpointer.New("/" + strings.Join(encTks, "/"))
-	return &ptr
+	return &ParseError{Position: perr.Position, Err: ErrTruncated}
 }
 
-type tokenTree struct {
-	tk       string
-	offset   *int
-	children map[string]*tokenTree
+// visitEntry is a requested tree node that has begun, whose position is set once it's found.
+type visitEntry struct {
+	tree *tokenTree
+	pos  *JSONPointerPosition
 }
 
-func (tree *tokenTree) add(ptr jsonpointer.Pointer) {
-	tks := ptr.DecodedTokens()
-	if len(tks) == 0 || (len(tks) == 1 && tks[0] == "") {
-		return
-	}
-	if tree.children == nil {
-		tree.children = map[string]*tokenTree{}
+// visited sets the position of the queued tree node, and visits the queued nodes that are found in the order of their
+// beginnings, i.e. until the first one that is still being walked, e.g. an object that contains the found node.
+func (w *walker) visited(tree *tokenTree, pos JSONPointerPosition) error {
+	i := len(w.visits) - 1
+	for i >= 0 && w.visits[i].tree != tree {
+		i--
 	}
-	tk, remains := tks[0], tks[1:]
-	subTree, ok := tree.children[tk]
-	if !ok {
-		subTree = &tokenTree{tk: tk}
-		tree.children[tk] = subTree
+	if i < 0 {
+		// The node hasn't begun as a value, which is visited once the preceding ones are
+		w.visits = append(w.visits, visitEntry{tree: tree})
+		i = len(w.visits) - 1
 	}
-	remainPtr := newJSONPtr(remains)
-	if remainPtr != nil {
-		subTree.add(*remainPtr)
+	w.visits[i].pos = &pos
+	n := 0
+	for ; n < len(w.visits) && w.visits[n].pos != nil; n++ {
+		if err := w.visit(*w.visits[n].pos); err != nil {
+			return err
+		}
 	}
+	w.visits = w.visits[n:]
+	return nil
 }
 
-// flattenOffset flattens the token tree to a map whose key is a json pointer and its value is the offset.
-// For token tree nodes that have no offset (implies they doesn't exist in the json document), they are skipped.
-func (tree *tokenTree) flattenOffset(parentTks []string) map[string]int {
-	out := map[string]int{}
+// errAllFound is returned during walking when all the pending nodes are found.
+var errAllFound = errors.New("all pending nodes are found")
 
-	var tks []string
-	for _, tk := range parentTks {
-		// This is to skip the root node of the tree when building the pointer
-		if tk == "" {
-			continue
+// found is called when the offset of the tree node is filled in. It returns errAllFound if it is the last pending node.
+// If there is a visitor, the position of the tree node is resolved if requested, whose pointer is the current path.
+func (w *walker) found(tree *tokenTree) error {
+	if w.visit != nil && tree.requested {
+		ptr := jsonpointer.Pointer{}
+		if p := newJSONPtr(w.path); p != nil {
+			ptr = *p
 		}
-		tks = append(tks, tk)
-	}
-	tks = append(tks, tree.tk)
-
-	for _, child := range tree.children {
-		m := child.flattenOffset(tks)
-		for k, v := range m {
-			out[k] = v
+		if err := w.visited(tree, w.jsonPointerPosition(ptr, tree)); err != nil {
+			return err
 		}
 	}
-
-	if tree.offset != nil {
-		ptr := newJSONPtr(tks)
-		out[ptr.String()] = *tree.offset
+	if w.pending == nil || !w.pending[tree] {
+		return nil
 	}
-
-	return out
-}
-
-func buildTokenTree(ptrs []jsonpointer.Pointer) tokenTree {
-	root := tokenTree{}
-	for _, ptr := range ptrs {
-		root.add(ptr)
+	if w.objects > 0 && !w.pos.opts.firstDuplicateKey {
+		w.deferred = append(w.deferred, tree)
+		return nil
 	}
-	return root
+	delete(w.pending, tree)
+	if len(w.pending) == 0 && w.matches == 0 {
+		return errAllFound
+	}
+	return nil
 }
 
-func GetPositions(document string, ptrs []jsonpointer.Pointer) (map[string]JSONPointerPosition, error) {
-	if len(ptrs) == 0 {
-		return nil, nil
+// confirm regards the deferred nodes as found, once no object is being walked. It returns errAllFound if they are the
+// last pending nodes.
+func (w *walker) confirm() error {
+	if w.objects > 0 || len(w.deferred) == 0 {
+		return nil
 	}
-	tree := buildTokenTree(ptrs)
-	dec := json.NewDecoder(strings.NewReader(document))
-	dec.UseNumber()
-
-	if _, err := offsetValue(dec, &tree); err != nil {
-		return nil, err
+	for _, tree := range w.deferred {
+		delete(w.pending, tree)
 	}
+	w.deferred = w.deferred[:0]
+	if len(w.pending) == 0 && w.matches == 0 {
+		return errAllFound
+	}
+	return nil
+}
 
-	m := tree.flattenOffset(nil)
-	nm := map[string]int{}
-	// Only keep the specified pointers from the flattened offset map
-	for _, ptr := range ptrs {
-		if v, ok := m[ptr.String()]; ok {
-			nm[ptr.String()] = v
+// walk walks through the document to fill in the offsets of the token tree, including the root node.
+// The walk stops resolving the offsets once all the pending nodes are found, in which case the offsets of the nodes
+// that are not pending might be left unset. The rest of the document is then only drained to validate it, unless
+// WithAllowTrailing is specified, in which case it is not consumed at all.
+func (w *walker) walk(tree *tokenTree) error {
+	length, err := w.offsetValue(tree)
+	if err != nil {
+		if err != errAllFound {
+			return err
+		}
+		if w.exhaustive || w.pos.opts.allowTrailing {
+			return nil
+		}
+		// The rest of the document is still validated, so that whether it's accepted doesn't depend on the pointers
+		if err := w.drainRest(); err != nil {
+			return err
 		}
+		return w.checkTrailing()
 	}
-	m = nm
-
-	type offsetItem struct {
-		ptr    string
-		offset int
+	offset := w.dec.InputOffset() - length
+	tree.offset = &offset
+	tree.length = length
+	if err := w.found(tree); err != nil && err != errAllFound {
+		return err
 	}
-	ol := []offsetItem{}
-	for ptr, offset := range m {
-		ol = append(ol, offsetItem{
-			ptr:    ptr,
-			offset: offset,
-		})
+	if w.exhaustive || w.pos.opts.allowTrailing {
+		return nil
 	}
-	sort.Slice(ol, func(i, j int) bool {
-		return ol[i].offset < ol[j].offset
-	})
-
-	var sc scanner.Scanner
-	sc.Init(strings.NewReader(document))
-
-	out := map[string]JSONPointerPosition{}
+	return w.checkTrailing()
+}
 
-	start := 0
-	for _, ov := range ol {
-		for i := start; i < ov.offset; i++ {
-			sc.Next()
-		}
-		ptr, err := jsonpointer.New(ov.ptr)
+// drainRest drains the rest of the top-level value after the walk stops early, i.e. the rest of the objects/arrays
+// being walked, whose delimiters are only counted by the depth.
+func (w *walker) drainRest() error {
+	for w.depth > 0 {
+		tk, err := w.token()
 		if err != nil {
-			return nil, err
+			return err
 		}
-		pos := sc.Pos()
-		out[ptr.String()] = JSONPointerPosition{
-			Ptr: ptr,
-			Position: Position{
-				Line:   pos.Line,
-				Column: pos.Column,
-			},
+		switch tk {
+		case json.Delim('{'), json.Delim('['):
+			if err := w.enter(w.dec.InputOffset() - 1); err != nil {
+				return err
+			}
+		case json.Delim('}'), json.Delim(']'):
+			w.depth--
 		}
-		start = ov.offset
 	}
-	return out, nil
+	return nil
+}
+
+// checkTrailing returns a *ParseError if there is anything other than whitespaces and comments after the value.
+func (w *walker) checkTrailing() error {
+	offset := w.dec.InputOffset()
+	_, err := w.dec.Token()
+	if err == io.EOF {
+		return nil
+	}
+	if err != nil {
+		return w.parseError(err)
+	}
+	// Another value follows, whose offset is found from the source text
+	if offset < w.pos.offset {
+		offset = w.pos.offset
+	}
+	return &ParseError{Position: w.pos.position(w.pos.skipSpace(offset)), Err: ErrTrailingContent}
 }
 
 // offsetValue fill ins the offset(s) of the specified tree for a JSON value.
-// Meanwhile, it returns the value length.
-func offsetValue(dec *json.Decoder, tree *tokenTree) (int, error) {
-	tk, err := dec.Token()
+// Meanwhile, it returns the value length. The value starts at the first byte of its token, which is the end of the
+// token minus the length, so that the whitespaces and the comments skipped by the decoder before it are excluded.
+// If all the pending nodes are found within the value, it returns errAllFound without consuming the rest of the value.
+func (w *walker) offsetValue(tree *tokenTree) (int64, error) {
+	dec := w.dec
+	tk, err := w.token()
 	if err != nil {
 		return 0, err
 	}
-	var length int
+	w.markColon(tree)
+	var length int64
+	var kind Kind
 	switch tk := tk.(type) {
 	case json.Delim:
 		switch tk {
 		case '{':
-			startOffset := int(dec.InputOffset())
-			err = offsetObject(dec, tree.children)
+			// The occurrences of a duplicate key after the reported one don't change it
+			if tree.offset == nil {
+				tree.delim = tk
+			}
+			startOffset := dec.InputOffset()
+			if err := w.enter(startOffset - 1); err != nil {
+				return 0, err
+			}
+			w.pos.mark(startOffset - 1)
+			if err := w.began(tree, KindObject, startOffset-1); err != nil {
+				return 0, err
+			}
+			w.captureRaw(tree, startOffset-1)
+			w.objects++
+			err = w.offsetObject(tree, startOffset-1)
 			if err != nil {
 				return 0, err
 			}
 			// Consumes the ending delim
-			if _, err := dec.Token(); err != nil {
+			if _, err := w.token(); err != nil {
 				return 0, err
 			}
-			endOffset := int(dec.InputOffset())
+			w.depth--
+			w.objects--
+			endOffset := dec.InputOffset()
+			w.pos.mark(endOffset - 1)
+			w.capturedRaw(tree, startOffset-1, endOffset)
 			length = endOffset - startOffset + 1
+			if err := w.confirm(); err != nil {
+				return 0, err
+			}
 		case '[':
-			startOffset := int(dec.InputOffset())
-			err = offsetArray(dec, tree.children)
+			// The occurrences of a duplicate key after the reported one don't change it
+			if tree.offset == nil {
+				tree.delim = tk
+			}
+			startOffset := dec.InputOffset()
+			if err := w.enter(startOffset - 1); err != nil {
+				return 0, err
+			}
+			w.pos.mark(startOffset - 1)
+			if err := w.began(tree, KindArray, startOffset-1); err != nil {
+				return 0, err
+			}
+			w.captureRaw(tree, startOffset-1)
+			err = w.offsetArray(tree, startOffset-1)
 			if err != nil {
 				return 0, err
 			}
 			// Consumes the ending delim
-			if _, err := dec.Token(); err != nil {
+			if _, err := w.token(); err != nil {
 				return 0, err
 			}
-			endOffset := int(dec.InputOffset())
+			w.depth--
+			endOffset := dec.InputOffset()
+			w.pos.mark(endOffset - 1)
+			w.capturedRaw(tree, startOffset-1, endOffset)
 			length = endOffset - startOffset + 1
 		default:
 			return 0, fmt.Errorf("unexpected delim token %#v", tk)
 		}
+		return length, nil
 	case bool:
+		kind = KindBoolean
 		if tk {
 			length = 4 // true
 		} else {
 			length = 5 // false
 		}
 	case json.Number:
-		length = len(tk.String())
+		kind = KindNumber
+		// The number is kept as its source text with UseNumber, including the sign and the exponent
+		length = int64(len(tk.String()))
 	case string:
-		length = len(tk) + 2 // quotes
+		kind = KindString
+		// The decoded string can be shorter than its source text due to escapes
+		endOffset := dec.InputOffset()
+		length = endOffset - w.stringStart(endOffset)
 	case nil:
+		kind = KindNull
 		length = 4 // null
 	default:
 		return 0, fmt.Errorf("invalid token %#v", tk)
 	}
+	w.scalar(tree, kind)
+	endOffset := dec.InputOffset()
+	w.pos.mark(endOffset - length)
+	if err := w.began(tree, kind, endOffset-length); err != nil {
+		return 0, err
+	}
+	w.captureRaw(tree, endOffset-length)
+	w.pos.mark(endOffset - 1)
+	w.capturedRaw(tree, endOffset-length, endOffset)
 	return length, nil
 }
 
-func offsetObject(dec *json.Decoder, trees map[string]*tokenTree) error {
-	var tree *tokenTree
-	for dec.More() {
-		tk, err := dec.Token()
+// began calls the begin callback, if any, with the value of the current path that begins at the marked offset. If
+// there is a visitor, the tree node is queued if requested, so that it's visited in the order of the beginnings.
+func (w *walker) began(tree *tokenTree, kind Kind, offset int64) error {
+	if w.visit != nil && tree.requested && tree.offset == nil {
+		w.visits = append(w.visits, visitEntry{tree: tree})
+	}
+	if w.begin == nil {
+		return nil
+	}
+	ptr := jsonpointer.Pointer{}
+	if p := newJSONPtr(w.path); p != nil {
+		ptr = *p
+	}
+	if err := w.begin(ptr, kind, w.pos.positions[offset]); err != nil {
+		return err
+	}
+	// The positions are not referenced afterwards, and the ones resolved later are not affected
+	for k := range w.pos.positions {
+		delete(w.pos.positions, k)
+	}
+	return nil
+}
+
+// scalar records the JSON type of the scalar value of the node.
+func (w *walker) scalar(tree *tokenTree, kind Kind) {
+	// The occurrences of a duplicate key after the reported one don't change it
+	if tree.offset == nil {
+		tree.scalar = kind
+	}
+}
+
+// tokenStarter is implemented by the TokenReaders that know the offset of the first byte of the last returned token,
+// which is required if the strings are not always double-quoted.
+type tokenStarter interface {
+	tokenStart() int64
+}
+
+// stringStart returns the offset of the first byte of the last returned string token, which ends at the end offset.
+func (w *walker) stringStart(end int64) int64 {
+	if ts, ok := w.dec.(tokenStarter); ok {
+		return ts.tokenStart()
+	}
+	return w.pos.stringStart(end)
+}
+
+// markColon marks the offset of the colon preceding the value of the tree node, if the node is an object member
+// whose value has just begun. The colon is searched from the offset held by offsetObject.
+func (w *walker) markColon(tree *tokenTree) {
+	if w.pos.hold < 0 {
+		return
+	}
+	colon := w.pos.separator(w.pos.hold, ':')
+	w.pos.hold = -1
+	if colon < 0 {
+		return
+	}
+	w.pos.mark(colon)
+	// The occurrences of a duplicate key after the reported one don't change it
+	if tree.offset == nil {
+		tree.colonOffset = &colon
+	}
+}
+
+// captureRaw starts capturing the raw text of the tree node from the start offset, if needed.
+func (w *walker) captureRaw(tree *tokenTree, start int64) {
+	if w.raw && tree.requested {
+		w.pos.capture(start)
+	}
+}
+
+// capturedRaw stops capturing the raw text of the tree node started by captureRaw, and sets it to the node.
+func (w *walker) capturedRaw(tree *tokenTree, start, end int64) {
+	if w.raw && tree.requested {
+		raw := w.pos.captured(start, end)
+		// The occurrences of a duplicate key after the reported one don't change it
+		if tree.offset == nil {
+			tree.raw = raw
+		}
+	}
+}
+
+// offsetObject fills in the offsets of the members of the object, whose opening delimiter is at the start offset and
+// is consumed.
+func (w *walker) offsetObject(parent *tokenTree, start int64) error {
+	dec := w.dec
+	var tree, first *tokenTree
+	keys := w.newKeys()
+	for n := 0; dec.More(); n++ {
+		tk, err := w.token()
 		if err != nil {
 			return err
 		}
 		switch tk := tk.(type) {
 		case string:
-			var ok bool
-			tree, ok = trees[tk]
-			if !ok {
-				if err := drainValue(dec); err != nil {
+			if err := w.checkKey(keys, tk); err != nil {
+				return err
+			}
+			tree = w.child(parent, w.key(parent, tk))
+			// A duplicate key is walked again to report its last occurrence. With WithFirstDuplicateKey, it's only
+			// walked if all the occurrences are to be found.
+			if tree != nil && tree.offset != nil {
+				switch {
+				case !w.pos.opts.firstDuplicateKey:
+					tree.reset(w.duplicateKeys)
+				case !w.duplicateKeys:
+					tree = nil
+				}
+			}
+			comma := w.comma()
+			if err := w.foundFirst(first, comma); err != nil {
+				return err
+			}
+			first = nil
+			if tree == nil {
+				if err := w.drainValue(); err != nil {
 					return err
 				}
+				w.pos.hold = dec.InputOffset()
 				continue
 			}
-			length, err := offsetValue(dec, tree)
+			if comma >= 0 {
+				w.pos.mark(comma)
+			}
+			keyOffset := w.stringStart(dec.InputOffset())
+			w.pos.mark(keyOffset)
+			// The decoded key can be shorter than its source text due to escapes
+			keyEndOffset := dec.InputOffset() - 1
+			w.pos.mark(keyEndOffset)
+			// Keep the bytes after the key until the colon is found by markColon
+			w.pos.hold = dec.InputOffset()
+			w.path = append(w.path, tree.tk)
+			length, err := w.offsetValue(tree)
 			if err != nil {
 				return err
 			}
-			offset := int(dec.InputOffset()) - length
+			// Keep the bytes after the value until the following comma is found by comma
+			w.pos.hold = dec.InputOffset()
+			offset := dec.InputOffset() - length
+			if tree.offset != nil {
+				tree.dupOffsets = append(tree.dupOffsets, offset)
+				w.path = w.path[:len(w.path)-1]
+				continue
+			}
 			tree.offset = &offset
+			tree.length = length
+			tree.keyOffset = &keyOffset
+			tree.keyEndOffset = &keyEndOffset
+			tree.parentOffset = &start
+			w.path = w.path[:len(w.path)-1]
+			if n == 0 {
+				first = tree
+				continue
+			}
+			if comma >= 0 {
+				tree.commaOffset = &comma
+			}
+			w.path = append(w.path, tree.tk)
+			if err := w.found(tree); err != nil {
+				return err
+			}
+			w.path = w.path[:len(w.path)-1]
 		default:
 			return fmt.Errorf("invalid object key token %#v", tk)
 		}
 	}
-	return nil
+	// The first member might be followed by a trailing comma
+	return w.foundFirst(first, w.comma())
 }
 
-func offsetArray(dec *json.Decoder, trees map[string]*tokenTree) error {
+// appendToken is the reference token that refers to the nonexistent element after the last array element.
+const appendToken = "-"
+
+// offsetArray fills in the offsets of the elements of the array, whose opening delimiter is at the start offset and
+// is consumed.
+func (w *walker) offsetArray(parent *tokenTree, start int64) error {
+	dec := w.dec
+	// The end offset of the last element is tracked for the "-" token, before More skips the following whitespaces
+	end := start + 1
+	appendTree := parent.children[appendToken]
+	if appendTree != nil {
+		w.pos.mark(end)
+	}
+	var first *tokenTree
 	i := -1
 	for dec.More() {
 		i++
-		idx := strconv.Itoa(i)
-		tree, ok := trees[idx]
-		if !ok {
-			if err := drainValue(dec); err != nil {
+		tk := strconv.Itoa(i)
+		tree := w.child(parent, tk)
+		comma := w.comma()
+		if err := w.foundFirst(first, comma); err != nil {
+			return err
+		}
+		first = nil
+		if tree == nil {
+			if err := w.drainValue(); err != nil {
 				return err
 			}
+			if appendTree != nil {
+				end = dec.InputOffset()
+				w.pos.mark(end)
+			}
+			w.pos.hold = dec.InputOffset()
 			continue
 		}
-		length, err := offsetValue(dec, tree)
+		if comma >= 0 {
+			w.pos.mark(comma)
+		}
+		w.path = append(w.path, tk)
+		length, err := w.offsetValue(tree)
 		if err != nil {
 			return err
 		}
-		offset := int(dec.InputOffset()) - length
+		if appendTree != nil {
+			end = dec.InputOffset()
+			w.pos.mark(end)
+		}
+		// Keep the bytes after the value until the following comma is found by comma
+		w.pos.hold = dec.InputOffset()
+		offset := dec.InputOffset() - length
+		// The element might be walked again within a duplicate object key
+		if tree.offset != nil {
+			tree.dupOffsets = append(tree.dupOffsets, offset)
+			w.path = w.path[:len(w.path)-1]
+			continue
+		}
 		tree.offset = &offset
+		tree.length = length
+		tree.parentOffset = &start
+		if i == 0 {
+			first = tree
+			w.path = w.path[:len(w.path)-1]
+			continue
+		}
+		if comma >= 0 {
+			tree.commaOffset = &comma
+		}
+		if err := w.found(tree); err != nil {
+			return err
+		}
+		w.path = w.path[:len(w.path)-1]
+	}
+	// The first element might be followed by a trailing comma
+	if err := w.foundFirst(first, w.comma()); err != nil {
+		return err
 	}
+	if appendTree == nil {
+		return nil
+	}
+	if appendTree.offset != nil {
+		appendTree.dupOffsets = append(appendTree.dupOffsets, end)
+		return nil
+	}
+	appendTree.offset = &end
+	appendTree.parentOffset = &start
+	w.path = append(w.path, appendToken)
+	if err := w.found(appendTree); err != nil {
+		return err
+	}
+	w.path = w.path[:len(w.path)-1]
+	return nil
+}
+
+// comma returns the offset of the comma between the previous member/element and the current one (or the closing
+// delimiter), which is searched from the hold offset set at the end of the previous one. It returns -1 if there is no
+// such comma, e.g. for the first member/element.
+func (w *walker) comma() int64 {
+	if w.pos.hold < 0 {
+		return -1
+	}
+	comma := w.pos.separator(w.pos.hold, ',')
+	w.pos.hold = -1
+	return comma
+}
+
+// foundFirst calls found for the first member/element of an object/array if it's not nil, which is deferred until the
+// comma that follows it is resolved.
+func (w *walker) foundFirst(tree *tokenTree, comma int64) error {
+	if tree == nil {
+		return nil
+	}
+	if comma >= 0 {
+		w.pos.mark(comma)
+		tree.commaOffset = &comma
+	}
+	w.path = append(w.path, tree.tk)
+	if err := w.found(tree); err != nil {
+		return err
+	}
+	w.path = w.path[:len(w.path)-1]
 	return nil
 }
 
+// key returns the token of the child of the parent that the object key matches. It's the key itself, unless the
+// WithCaseInsensitiveKeys option is specified and there is no exact match, in which case the matching token that is
+// the least in byte order is returned, if any.
+func (w *walker) key(parent *tokenTree, key string) string {
+	if !w.pos.opts.caseInsensitiveKeys {
+		return key
+	}
+	if _, ok := parent.children[key]; ok {
+		return key
+	}
+	match := key
+	found := false
+	for tk := range parent.children {
+		if tk == wildcardPattern || tk == recursiveWildcardPattern || !strings.EqualFold(tk, key) {
+			continue
+		}
+		if !found || tk < match {
+			match, found = tk, true
+		}
+	}
+	return match
+}
+
+// child returns the child node of the parent for the token, or nil if the value of that token doesn't need to be walked.
+// If the parent has a wildcard child, it is merged into the returned child node.
+// If the parent has a recursive wildcard child, its children are merged into the parent (matching zero level), and
+// itself is merged into the returned child node (matching more levels).
+func (w *walker) child(parent *tokenTree, tk string) *tokenTree {
+	recursive, hasRecursive := parent.children[recursiveWildcardPattern]
+	if hasRecursive {
+		parent.merge(recursive)
+	}
+	tree, ok := parent.children[tk]
+	wildcard, hasWildcard := parent.children[wildcardPattern]
+	// The members/elements of an object/array to be expanded are all requested
+	expand := w.pos.opts.expands(parent)
+	if !ok {
+		if !hasWildcard && !hasRecursive && !w.all && !expand {
+			return nil
+		}
+		tree = &tokenTree{tk: tk, requested: w.all}
+	}
+	if w.wildcards != nil && !expand && !w.countMatch(tree, append(w.path[:len(w.path):len(w.path)], tk)) {
+		return nil
+	}
+	// The values reported once they begin are not kept
+	if !ok && w.begin == nil {
+		if parent.children == nil {
+			parent.children = map[string]*tokenTree{}
+		}
+		parent.children[tk] = tree
+	}
+	if expand {
+		tree.requested = true
+	}
+	if hasWildcard && tree != wildcard {
+		tree.merge(wildcard)
+	}
+	if hasRecursive && tree != recursive {
+		tree.merge(&tokenTree{children: map[string]*tokenTree{recursiveWildcardPattern: recursive}})
+	}
+	return tree
+}
+
 // drainValue drains a single value, including object and array.
-func drainValue(dec *json.Decoder) error {
-	tk, err := dec.Token()
+func (w *walker) drainValue() error {
+	tk, err := w.token()
 	if err != nil {
 		return err
 	}
 
-	switch tk := tk.(type) {
-	case json.Delim:
-		switch tk {
-		case '{':
-			if err := drainInContainer(dec); err != nil {
-				return err
-			}
-		case '[':
-			if err := drainInContainer(dec); err != nil {
-				return err
-			}
+	if delim, ok := tk.(json.Delim); ok {
+		if err := w.enter(w.dec.InputOffset() - 1); err != nil {
+			return err
+		}
+		if err := w.drainInContainer(delim); err != nil {
+			return err
 		}
 	}
 	return nil
 }
 
 // drainInContainer drains a json container (object/array) by assuming the beginning delimiter is consumed.
-func drainInContainer(dec *json.Decoder) error {
-	for dec.More() {
-		tk, err := dec.Token()
+func (w *walker) drainInContainer(open json.Delim) error {
+	// The keys of the object are checked in strict mode, which are the tokens at the even indexes
+	var keys map[string]bool
+	if open == '{' {
+		keys = w.newKeys()
+	}
+	for n := 0; w.dec.More(); n++ {
+		tk, err := w.token()
 		if err != nil {
 			return err
 		}
-		switch tk := tk.(type) {
-		case json.Delim:
-			switch tk {
-			case '{':
-				if err := drainInContainer(dec); err != nil {
-					return err
-				}
-			case '[':
-				if err := drainInContainer(dec); err != nil {
-					return err
-				}
+		if key, ok := tk.(string); ok && keys != nil && n%2 == 0 {
+			if err := w.checkKey(keys, key); err != nil {
+				return err
+			}
+		}
+		if delim, ok := tk.(json.Delim); ok {
+			if err := w.enter(w.dec.InputOffset() - 1); err != nil {
+				return err
+			}
+			if err := w.drainInContainer(delim); err != nil {
+				return err
 			}
 		}
+		if keys != nil && n%2 == 1 {
+			// Keep the bytes after the value, so that the start of the next key can be found
+			w.pos.hold = w.dec.InputOffset()
+		}
 	}
 	// Consumes the ending delim
-	if _, err := dec.Token(); err != nil {
+	if _, err := w.token(); err != nil {
 		return err
 	}
+	w.depth--
+	return nil
+}
+
+// newKeys returns the set of the keys of an object being walked, which is only non-nil in strict mode.
+func (w *walker) newKeys() map[string]bool {
+	if !w.strictKeys {
+		return nil
+	}
+	return map[string]bool{}
+}
+
+// checkKey returns a *DuplicateKeyError if the key just read is already in the set of the keys of the object, and
+// adds it otherwise. It is a no-op if the set is nil.
+func (w *walker) checkKey(keys map[string]bool, key string) error {
+	if keys == nil {
+		return nil
+	}
+	if keys[key] {
+		return &DuplicateKeyError{Key: key, Position: w.pos.position(w.stringStart(w.dec.InputOffset()))}
+	}
+	keys[key] = true
+	return nil
+}
+
+// DuplicateKeyError is returned with the WithStrictDuplicateKeys option, when an object has repeated keys.
+type DuplicateKeyError struct {
+	// Key is the repeated key.
+	Key string
+	// Position is the position of the key of the second occurrence.
+	Position Position
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("%d:%d: duplicate object key %q", e.Position.Line, e.Position.Column, e.Key)
+}
+
+// ErrTrailingContent is the error of a ParseError, when the document has another value after the top-level value.
+var ErrTrailingContent = errors.New("unexpected content after the top-level value")
+
+// ErrTruncated is the error of a ParseError with the WithPartial option, when the document ends before the top-level
+// value is complete.
+var ErrTruncated = errors.New("unexpected end of a truncated document")
+
+// ParseError is returned when the document is malformed.
+type ParseError struct {
+	// Position is the position where the error is detected.
+	Position Position
+	// Err is the original error returned by the decoder.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%d:%d: %v", e.Position.Line, e.Position.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// MaxDepthError is returned when the nesting depth of the objects/arrays exceeds the limit set by WithMaxDepth, or the
+// default one.
+type MaxDepthError struct {
+	MaxDepth int
+	// Offset is the byte offset of the opening delimiter that exceeds the limit.
+	Offset int64
+}
+
+func (e *MaxDepthError) Error() string {
+	return fmt.Sprintf("exceeded max depth %d at offset %d", e.MaxDepth, e.Offset)
+}
+
+// defaultMaxDepth is the maximum nesting depth without the WithMaxDepth option, which is the same as encoding/json.
+const defaultMaxDepth = 10000
+
+// enter is called when an object/array is opened at the offset, which increases the depth.
+func (w *walker) enter(offset int64) error {
+	w.depth++
+	maxDepth := w.maxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultMaxDepth
+	}
+	if w.depth > maxDepth {
+		return &MaxDepthError{MaxDepth: maxDepth, Offset: offset + w.pos.opts.baseOffset()}
+	}
 	return nil
 }
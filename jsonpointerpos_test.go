@@ -1,9 +1,14 @@
 package jsonpointerpos
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
 	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/go-openapi/jsonpointer"
 	"github.com/stretchr/testify/require"
@@ -32,13 +37,16 @@ func TestBuildTokenTree(t *testing.T) {
 				tk: "",
 				children: map[string]*tokenTree{
 					"foo": {
-						tk: "foo",
+						tk:        "foo",
+						requested: true,
 						children: map[string]*tokenTree{
 							"a": {
-								tk: "a",
+								tk:        "a",
+								requested: true,
 							},
 							"b": {
-								tk: "b",
+								tk:        "b",
+								requested: true,
 							},
 						},
 					},
@@ -49,12 +57,44 @@ func TestBuildTokenTree(t *testing.T) {
 								tk: "a",
 								children: map[string]*tokenTree{
 									"b": {
-										tk: "b",
+										tk:        "b",
+										requested: true,
 									},
 								},
 							},
 						},
 					},
+					"": {
+						tk:        "",
+						requested: true,
+					},
+				},
+			},
+		},
+		{
+			name:  "empty keys",
+			input: []string{"", "/a/", "//"},
+			expect: tokenTree{
+				requested: true,
+				children: map[string]*tokenTree{
+					"a": {
+						tk: "a",
+						children: map[string]*tokenTree{
+							"": {
+								tk:        "",
+								requested: true,
+							},
+						},
+					},
+					"": {
+						tk: "",
+						children: map[string]*tokenTree{
+							"": {
+								tk:        "",
+								requested: true,
+							},
+						},
+					},
 				},
 			},
 		},
@@ -78,20 +118,20 @@ func TestOffsetValue(t *testing.T) {
 		name   string
 		input  string
 		ptrs   []string
-		length int
+		length int64
 		expect tokenTree
 	}{
 		{
 			name:   "empty object",
 			input:  "{}",
 			length: 2,
-			expect: tokenTree{},
+			expect: tokenTree{delim: '{'},
 		},
 		{
 			name:   "empty array",
 			input:  "[]",
 			length: 2,
-			expect: tokenTree{},
+			expect: tokenTree{delim: '['},
 		},
 		{
 			name:   "empty object with non-exist ptr",
@@ -99,9 +139,11 @@ func TestOffsetValue(t *testing.T) {
 			ptrs:   []string{"/foo"},
 			length: 2,
 			expect: tokenTree{
+				delim: '{',
 				children: map[string]*tokenTree{
 					"foo": {
-						tk: "foo",
+						tk:        "foo",
+						requested: true,
 					},
 				},
 			},
@@ -112,38 +154,101 @@ func TestOffsetValue(t *testing.T) {
 			ptrs:   []string{"/string", "/number", "/float", "/null", "/true", "/false", "/obj/x"},
 			length: 121,
 			expect: tokenTree{
+				delim: '{',
 				children: map[string]*tokenTree{
 					"string": {
-						tk:     "string",
-						offset: ptr(14),
+						tk:           "string",
+						requested:    true,
+						offset:       ptr[int64](14),
+						length:       5,
+						keyOffset:    ptr[int64](3),
+						keyEndOffset: ptr[int64](10),
+						colonOffset:  ptr[int64](12),
+						parentOffset: ptr[int64](0),
+						commaOffset:  ptr[int64](20),
+						scalar:       KindString,
 					},
 					"number": {
-						tk:     "number",
-						offset: ptr(33),
+						tk:           "number",
+						requested:    true,
+						offset:       ptr[int64](33),
+						length:       3,
+						keyOffset:    ptr[int64](22),
+						keyEndOffset: ptr[int64](29),
+						colonOffset:  ptr[int64](31),
+						parentOffset: ptr[int64](0),
+						commaOffset:  ptr[int64](20),
+						scalar:       KindNumber,
 					},
 					"float": {
-						tk:     "float",
-						offset: ptr(49),
+						tk:           "float",
+						requested:    true,
+						offset:       ptr[int64](49),
+						length:       4,
+						keyOffset:    ptr[int64](39),
+						keyEndOffset: ptr[int64](45),
+						colonOffset:  ptr[int64](47),
+						parentOffset: ptr[int64](0),
+						commaOffset:  ptr[int64](37),
+						scalar:       KindNumber,
 					},
 					"null": {
-						tk:     "null",
-						offset: ptr(64),
+						tk:           "null",
+						requested:    true,
+						offset:       ptr[int64](64),
+						length:       4,
+						keyOffset:    ptr[int64](55),
+						keyEndOffset: ptr[int64](60),
+						colonOffset:  ptr[int64](62),
+						parentOffset: ptr[int64](0),
+						commaOffset:  ptr[int64](53),
+						scalar:       KindNull,
 					},
 					"true": {
-						tk:     "true",
-						offset: ptr(80),
+						tk:           "true",
+						requested:    true,
+						offset:       ptr[int64](80),
+						length:       4,
+						keyOffset:    ptr[int64](71),
+						keyEndOffset: ptr[int64](76),
+						colonOffset:  ptr[int64](78),
+						parentOffset: ptr[int64](0),
+						commaOffset:  ptr[int64](69),
+						scalar:       KindBoolean,
 					},
 					"false": {
-						tk:     "false",
-						offset: ptr(96),
+						tk:           "false",
+						requested:    true,
+						offset:       ptr[int64](96),
+						length:       5,
+						keyOffset:    ptr[int64](86),
+						keyEndOffset: ptr[int64](92),
+						colonOffset:  ptr[int64](94),
+						parentOffset: ptr[int64](0),
+						commaOffset:  ptr[int64](84),
+						scalar:       KindBoolean,
 					},
 					"obj": {
-						tk:     "obj",
-						offset: ptr(112),
+						tk:           "obj",
+						offset:       ptr[int64](112),
+						length:       8,
+						keyOffset:    ptr[int64](104),
+						keyEndOffset: ptr[int64](108),
+						colonOffset:  ptr[int64](110),
+						parentOffset: ptr[int64](0),
+						commaOffset:  ptr[int64](101),
+						delim:        '{',
 						children: map[string]*tokenTree{
 							"x": {
-								tk:     "x",
-								offset: ptr(118),
+								tk:           "x",
+								requested:    true,
+								offset:       ptr[int64](118),
+								length:       1,
+								keyOffset:    ptr[int64](113),
+								keyEndOffset: ptr[int64](115),
+								colonOffset:  ptr[int64](116),
+								parentOffset: ptr[int64](112),
+								scalar:       KindNumber,
 							},
 						},
 					},
@@ -156,14 +261,24 @@ func TestOffsetValue(t *testing.T) {
 			ptrs:   []string{"/0/1"},
 			length: 14,
 			expect: tokenTree{
+				delim: '[',
 				children: map[string]*tokenTree{
 					"0": {
-						tk:     "0",
-						offset: ptr(1),
+						tk:           "0",
+						offset:       ptr[int64](1),
+						length:       5,
+						parentOffset: ptr[int64](0),
+						commaOffset:  ptr[int64](6),
+						delim:        '[',
 						children: map[string]*tokenTree{
 							"1": {
-								tk:     "1",
-								offset: ptr(4),
+								tk:           "1",
+								requested:    true,
+								offset:       ptr[int64](4),
+								length:       1,
+								parentOffset: ptr[int64](1),
+								commaOffset:  ptr[int64](3),
+								scalar:       KindNumber,
 							},
 						},
 					},
@@ -176,22 +291,42 @@ func TestOffsetValue(t *testing.T) {
 			ptrs:   []string{"/0/1/foo/0"},
 			length: 34,
 			expect: tokenTree{
+				delim: '[',
 				children: map[string]*tokenTree{
 					"0": {
-						tk:     "0",
-						offset: ptr(1),
+						tk:           "0",
+						offset:       ptr[int64](1),
+						length:       24,
+						parentOffset: ptr[int64](0),
+						commaOffset:  ptr[int64](25),
+						delim:        '[',
 						children: map[string]*tokenTree{
 							"1": {
-								tk:     "1",
-								offset: ptr(5),
+								tk:           "1",
+								offset:       ptr[int64](5),
+								length:       19,
+								parentOffset: ptr[int64](1),
+								commaOffset:  ptr[int64](3),
+								delim:        '{',
 								children: map[string]*tokenTree{
 									"foo": {
-										tk:     "foo",
-										offset: ptr(13),
+										tk:           "foo",
+										offset:       ptr[int64](13),
+										length:       10,
+										keyOffset:    ptr[int64](6),
+										keyEndOffset: ptr[int64](10),
+										colonOffset:  ptr[int64](11),
+										parentOffset: ptr[int64](5),
+										delim:        '[',
 										children: map[string]*tokenTree{
 											"0": {
-												tk:     "0",
-												offset: ptr(14),
+												tk:           "0",
+												requested:    true,
+												offset:       ptr[int64](14),
+												length:       3,
+												parentOffset: ptr[int64](13),
+												commaOffset:  ptr[int64](17),
+												scalar:       KindString,
 											},
 										},
 									},
@@ -206,8 +341,6 @@ func TestOffsetValue(t *testing.T) {
 
 	for _, tt := range cases {
 		t.Run(tt.name, func(t *testing.T) {
-			dec := json.NewDecoder(strings.NewReader(tt.input))
-			dec.UseNumber()
 			var ptrs []jsonpointer.Pointer
 			for _, v := range tt.ptrs {
 				ptr, err := jsonpointer.New(v)
@@ -215,7 +348,8 @@ func TestOffsetValue(t *testing.T) {
 				ptrs = append(ptrs, ptr)
 			}
 			tree := buildTokenTree(ptrs)
-			length, err := offsetValue(dec, &tree)
+			w := newWalker(strings.NewReader(tt.input), newOptions(nil))
+			length, err := w.offsetValue(&tree)
 			require.NoError(t, err)
 			require.Equal(t, tt.length, length)
 			require.Equal(t, tt.expect, tree)
@@ -223,11 +357,22 @@ func TestOffsetValue(t *testing.T) {
 	}
 }
 
+func TestNewOptions(t *testing.T) {
+	require.Equal(t, options{tabWidth: 1}, newOptions(nil))
+	require.Equal(t, options{tabWidth: 1}, newOptions([]Option{nil}))
+	require.Equal(t, options{
+		columnUnit: columnUTF16,
+		tabWidth:   1,
+		zeroBased:  true,
+	}, newOptions([]Option{WithTabWidth(4), WithUTF16Columns(), WithZeroBased(), WithTabWidth(0)}))
+}
+
 func TestGetPositions(t *testing.T) {
 	cases := []struct {
 		name   string
 		input  string
 		ptrs   []string
+		opts   []Option
 		expect map[string]JSONPointerPosition
 	}{
 		{
@@ -256,20 +401,129 @@ func TestGetPositions(t *testing.T) {
     "x": 3
   }
 }`,
-			ptrs: []string{"/b", "/c/x", "/non-exist"},
+			ptrs: []string{"/b", "/c", "/c/x", "/non-exist"},
 			expect: map[string]JSONPointerPosition{
 				"/b": {
-					Ptr: *newJSONPtr([]string{"b"}),
+					Ptr:   *newJSONPtr([]string{"b"}),
+					Depth: 1,
+					Kind:  KindNumber,
 					Position: Position{
 						Line:   4,
 						Column: 8,
+						Offset: 20,
+					},
+					EndPosition: Position{
+						Line:   4,
+						Column: 8,
+						Offset: 20,
+					},
+					ByteLength: 1,
+					KeyPosition: &Position{
+						Line:   4,
+						Column: 3,
+						Offset: 15,
+					},
+					KeyEndPosition: &Position{
+						Line:   4,
+						Column: 5,
+						Offset: 17,
+					},
+					ColonPosition: &Position{
+						Line:   4,
+						Column: 6,
+						Offset: 18,
+					},
+					ParentPosition: &Position{
+						Line:   2,
+						Column: 1,
+						Offset: 1,
+					},
+					CommaPosition: &Position{
+						Line:   3,
+						Column: 9,
+						Offset: 11,
+					},
+				},
+				"/c": {
+					Ptr:   *newJSONPtr([]string{"c"}),
+					Depth: 1,
+					Kind:  KindObject,
+					Position: Position{
+						Line:   5,
+						Column: 8,
+						Offset: 30,
+					},
+					EndPosition: Position{
+						Line:   7,
+						Column: 3,
+						Offset: 45,
+					},
+					ClosePosition: &Position{
+						Line:   7,
+						Column: 3,
+						Offset: 45,
+					},
+					ByteLength: 16,
+					KeyPosition: &Position{
+						Line:   5,
+						Column: 3,
+						Offset: 25,
+					},
+					KeyEndPosition: &Position{
+						Line:   5,
+						Column: 5,
+						Offset: 27,
+					},
+					ColonPosition: &Position{
+						Line:   5,
+						Column: 6,
+						Offset: 28,
+					},
+					ParentPosition: &Position{
+						Line:   2,
+						Column: 1,
+						Offset: 1,
+					},
+					CommaPosition: &Position{
+						Line:   4,
+						Column: 9,
+						Offset: 21,
 					},
 				},
 				"/c/x": {
-					Ptr: *newJSONPtr([]string{"c", "x"}),
+					Ptr:   *newJSONPtr([]string{"c", "x"}),
+					Depth: 2,
+					Kind:  KindNumber,
 					Position: Position{
 						Line:   6,
 						Column: 10,
+						Offset: 41,
+					},
+					EndPosition: Position{
+						Line:   6,
+						Column: 10,
+						Offset: 41,
+					},
+					ByteLength: 1,
+					KeyPosition: &Position{
+						Line:   6,
+						Column: 5,
+						Offset: 36,
+					},
+					KeyEndPosition: &Position{
+						Line:   6,
+						Column: 7,
+						Offset: 38,
+					},
+					ColonPosition: &Position{
+						Line:   6,
+						Column: 8,
+						Offset: 39,
+					},
+					ParentPosition: &Position{
+						Line:   5,
+						Column: 8,
+						Offset: 30,
 					},
 				},
 			},
@@ -284,10 +538,29 @@ func TestGetPositions(t *testing.T) {
 			ptrs: []string{"/0/1"},
 			expect: map[string]JSONPointerPosition{
 				"/0/1": {
-					Ptr: *newJSONPtr([]string{"0", "1"}),
+					Ptr:   *newJSONPtr([]string{"0", "1"}),
+					Depth: 2,
+					Kind:  KindNumber,
 					Position: Position{
 						Line:   3,
 						Column: 7,
+						Offset: 9,
+					},
+					EndPosition: Position{
+						Line:   3,
+						Column: 7,
+						Offset: 9,
+					},
+					ByteLength: 1,
+					ParentPosition: &Position{
+						Line:   3,
+						Column: 3,
+						Offset: 5,
+					},
+					CommaPosition: &Position{
+						Line:   3,
+						Column: 5,
+						Offset: 7,
 					},
 				},
 			},
@@ -304,34 +577,2764 @@ func TestGetPositions(t *testing.T) {
   ],
   [3, 4]
 ]`,
-			ptrs: []string{"/0/1/foo/0"},
+			ptrs: []string{"/0", "/0/1/foo/0"},
 			expect: map[string]JSONPointerPosition{
+				"/0": {
+					Ptr:   *newJSONPtr([]string{"0"}),
+					Depth: 1,
+					Kind:  KindArray,
+					Position: Position{
+						Line:   3,
+						Column: 3,
+						Offset: 5,
+					},
+					EndPosition: Position{
+						Line:   8,
+						Column: 3,
+						Offset: 52,
+					},
+					ClosePosition: &Position{
+						Line:   8,
+						Column: 3,
+						Offset: 52,
+					},
+					ByteLength: 48,
+					ParentPosition: &Position{
+						Line:   2,
+						Column: 1,
+						Offset: 1,
+					},
+					CommaPosition: &Position{
+						Line:   8,
+						Column: 4,
+						Offset: 53,
+					},
+				},
 				"/0/1/foo/0": {
-					Ptr: *newJSONPtr([]string{"0", "1", "foo", "0"}),
+					Ptr:   *newJSONPtr([]string{"0", "1", "foo", "0"}),
+					Depth: 4,
+					Kind:  KindString,
 					Position: Position{
 						Line:   6,
 						Column: 15,
+						Offset: 34,
+					},
+					EndPosition: Position{
+						Line:   6,
+						Column: 17,
+						Offset: 36,
+					},
+					ByteLength: 3,
+					ParentPosition: &Position{
+						Line:   6,
+						Column: 14,
+						Offset: 33,
+					},
+					CommaPosition: &Position{
+						Line:   6,
+						Column: 18,
+						Offset: 37,
 					},
 				},
 			},
 		},
-	}
-
-	for _, tt := range cases {
-		t.Run(tt.name, func(t *testing.T) {
-			var ptrs []jsonpointer.Pointer
-			for _, v := range tt.ptrs {
-				ptr, err := jsonpointer.New(v)
-				require.NoError(t, err)
-				ptrs = append(ptrs, ptr)
-			}
-			out, err := GetPositions(tt.input, ptrs)
-			require.NoError(t, err)
-			require.Equal(t, tt.expect, out)
-		})
-	}
-}
-
-func ptr[T any](v T) *T {
-	return &v
+		{
+			name:  "key with leading whitespace",
+			input: "{\n\t  \"foo\" :   \"bar\"}",
+			ptrs:  []string{"/foo"},
+			expect: map[string]JSONPointerPosition{
+				"/foo": {
+					Ptr:   *newJSONPtr([]string{"foo"}),
+					Depth: 1,
+					Kind:  KindString,
+					Position: Position{
+						Line:   2,
+						Column: 14,
+						Offset: 15,
+					},
+					EndPosition: Position{
+						Line:   2,
+						Column: 18,
+						Offset: 19,
+					},
+					ByteLength: 5,
+					KeyPosition: &Position{
+						Line:   2,
+						Column: 4,
+						Offset: 5,
+					},
+					KeyEndPosition: &Position{
+						Line:   2,
+						Column: 8,
+						Offset: 9,
+					},
+					ColonPosition: &Position{
+						Line:   2,
+						Column: 10,
+						Offset: 11,
+					},
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 1,
+						Offset: 0,
+					},
+				},
+			},
+		},
+		{
+			name:  "accented key with rune columns",
+			input: `{"café": "x", "b": 2}`,
+			ptrs:  []string{"/b"},
+			expect: map[string]JSONPointerPosition{
+				"/b": {
+					Ptr:   *newJSONPtr([]string{"b"}),
+					Depth: 1,
+					Kind:  KindNumber,
+					Position: Position{
+						Line:   1,
+						Column: 20,
+						Offset: 20,
+					},
+					EndPosition: Position{
+						Line:   1,
+						Column: 20,
+						Offset: 20,
+					},
+					ByteLength: 1,
+					KeyPosition: &Position{
+						Line:   1,
+						Column: 15,
+						Offset: 15,
+					},
+					KeyEndPosition: &Position{
+						Line:   1,
+						Column: 17,
+						Offset: 17,
+					},
+					ColonPosition: &Position{
+						Line:   1,
+						Column: 18,
+						Offset: 18,
+					},
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 1,
+						Offset: 0,
+					},
+					CommaPosition: &Position{
+						Line:   1,
+						Column: 13,
+						Offset: 13,
+					},
+				},
+			},
+		},
+		{
+			name:  "emoji key with rune columns",
+			input: `{"😀": 1, "a": 2}`,
+			ptrs:  []string{"/a"},
+			expect: map[string]JSONPointerPosition{
+				"/a": {
+					Ptr:   *newJSONPtr([]string{"a"}),
+					Depth: 1,
+					Kind:  KindNumber,
+					Position: Position{
+						Line:   1,
+						Column: 15,
+						Offset: 17,
+					},
+					EndPosition: Position{
+						Line:   1,
+						Column: 15,
+						Offset: 17,
+					},
+					ByteLength: 1,
+					KeyPosition: &Position{
+						Line:   1,
+						Column: 10,
+						Offset: 12,
+					},
+					KeyEndPosition: &Position{
+						Line:   1,
+						Column: 12,
+						Offset: 14,
+					},
+					ColonPosition: &Position{
+						Line:   1,
+						Column: 13,
+						Offset: 15,
+					},
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 1,
+						Offset: 0,
+					},
+					CommaPosition: &Position{
+						Line:   1,
+						Column: 8,
+						Offset: 10,
+					},
+				},
+			},
+		},
+		{
+			name:  "emoji key with UTF-16 columns",
+			input: `{"😀": 1, "a": 2}`,
+			ptrs:  []string{"/a"},
+			opts:  []Option{WithUTF16Columns()},
+			expect: map[string]JSONPointerPosition{
+				"/a": {
+					Ptr:   *newJSONPtr([]string{"a"}),
+					Depth: 1,
+					Kind:  KindNumber,
+					Position: Position{
+						Line:   1,
+						Column: 16,
+						Offset: 17,
+					},
+					EndPosition: Position{
+						Line:   1,
+						Column: 16,
+						Offset: 17,
+					},
+					ByteLength: 1,
+					KeyPosition: &Position{
+						Line:   1,
+						Column: 11,
+						Offset: 12,
+					},
+					KeyEndPosition: &Position{
+						Line:   1,
+						Column: 13,
+						Offset: 14,
+					},
+					ColonPosition: &Position{
+						Line:   1,
+						Column: 14,
+						Offset: 15,
+					},
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 1,
+						Offset: 0,
+					},
+					CommaPosition: &Position{
+						Line:   1,
+						Column: 9,
+						Offset: 10,
+					},
+				},
+			},
+		},
+		{
+			name:  "CJK key with display width columns",
+			input: "{\"名前\": 1, \"e\u0301\": 2}",
+			ptrs:  []string{"/e\u0301"},
+			opts:  []Option{WithDisplayWidthColumns()},
+			expect: map[string]JSONPointerPosition{
+				"/e\u0301": {
+					Ptr:   *newJSONPtr([]string{"e\u0301"}),
+					Depth: 1,
+					Kind:  KindNumber,
+					Position: Position{
+						Line:   1,
+						Column: 18,
+						Offset: 21,
+					},
+					EndPosition: Position{
+						Line:   1,
+						Column: 18,
+						Offset: 21,
+					},
+					ByteLength: 1,
+					KeyPosition: &Position{
+						Line:   1,
+						Column: 13,
+						Offset: 14,
+					},
+					KeyEndPosition: &Position{
+						Line:   1,
+						Column: 15,
+						Offset: 18,
+					},
+					ColonPosition: &Position{
+						Line:   1,
+						Column: 16,
+						Offset: 19,
+					},
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 1,
+						Offset: 0,
+					},
+					CommaPosition: &Position{
+						Line:   1,
+						Column: 11,
+						Offset: 12,
+					},
+				},
+			},
+		},
+		{
+			name:  "tab indentation with tab width",
+			input: "{\n\t\t\"a\": 1\n}",
+			ptrs:  []string{"/a"},
+			opts:  []Option{WithTabWidth(4)},
+			expect: map[string]JSONPointerPosition{
+				"/a": {
+					Ptr:   *newJSONPtr([]string{"a"}),
+					Depth: 1,
+					Kind:  KindNumber,
+					Position: Position{
+						Line:   2,
+						Column: 14,
+						Offset: 9,
+					},
+					EndPosition: Position{
+						Line:   2,
+						Column: 14,
+						Offset: 9,
+					},
+					ByteLength: 1,
+					KeyPosition: &Position{
+						Line:   2,
+						Column: 9,
+						Offset: 4,
+					},
+					KeyEndPosition: &Position{
+						Line:   2,
+						Column: 11,
+						Offset: 6,
+					},
+					ColonPosition: &Position{
+						Line:   2,
+						Column: 12,
+						Offset: 7,
+					},
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 1,
+						Offset: 0,
+					},
+				},
+			},
+		},
+		{
+			name:  "line start",
+			input: "[\n1\n]",
+			ptrs:  []string{"/0"},
+			expect: map[string]JSONPointerPosition{
+				"/0": {
+					Ptr:   *newJSONPtr([]string{"0"}),
+					Depth: 1,
+					Kind:  KindNumber,
+					Position: Position{
+						Line:   2,
+						Column: 1,
+						Offset: 2,
+					},
+					EndPosition: Position{
+						Line:   2,
+						Column: 1,
+						Offset: 2,
+					},
+					ByteLength: 1,
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 1,
+						Offset: 0,
+					},
+				},
+			},
+		},
+		{
+			name:  "line start with zero based",
+			input: "[\n1\n]",
+			ptrs:  []string{"/0"},
+			opts:  []Option{WithZeroBased()},
+			expect: map[string]JSONPointerPosition{
+				"/0": {
+					Ptr:   *newJSONPtr([]string{"0"}),
+					Depth: 1,
+					Kind:  KindNumber,
+					Position: Position{
+						Line:   1,
+						Column: 0,
+						Offset: 2,
+					},
+					EndPosition: Position{
+						Line:   1,
+						Column: 0,
+						Offset: 2,
+					},
+					ByteLength: 1,
+					ParentPosition: &Position{
+						Line:   0,
+						Column: 0,
+						Offset: 0,
+					},
+				},
+			},
+		},
+		{
+			name: "comments",
+			input: `{
+  // the url
+  "url": /* inline */"http://a/*b*/",
+  "port": 8080 // trailing
+}`,
+			ptrs: []string{"/url", "/port"},
+			opts: []Option{WithComments()},
+			expect: map[string]JSONPointerPosition{
+				"/url": {
+					Ptr:   *newJSONPtr([]string{"url"}),
+					Depth: 1,
+					Kind:  KindString,
+					Position: Position{
+						Line:   3,
+						Column: 22,
+						Offset: 36,
+					},
+					EndPosition: Position{
+						Line:   3,
+						Column: 36,
+						Offset: 50,
+					},
+					ByteLength: 15,
+					KeyPosition: &Position{
+						Line:   3,
+						Column: 3,
+						Offset: 17,
+					},
+					KeyEndPosition: &Position{
+						Line:   3,
+						Column: 7,
+						Offset: 21,
+					},
+					ColonPosition: &Position{
+						Line:   3,
+						Column: 8,
+						Offset: 22,
+					},
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 1,
+						Offset: 0,
+					},
+					CommaPosition: &Position{
+						Line:   3,
+						Column: 37,
+						Offset: 51,
+					},
+					LeadingComments: []string{"// the url"},
+				},
+				"/port": {
+					Ptr:   *newJSONPtr([]string{"port"}),
+					Depth: 1,
+					Kind:  KindNumber,
+					Position: Position{
+						Line:   4,
+						Column: 11,
+						Offset: 63,
+					},
+					EndPosition: Position{
+						Line:   4,
+						Column: 14,
+						Offset: 66,
+					},
+					ByteLength: 4,
+					KeyPosition: &Position{
+						Line:   4,
+						Column: 3,
+						Offset: 55,
+					},
+					KeyEndPosition: &Position{
+						Line:   4,
+						Column: 8,
+						Offset: 60,
+					},
+					ColonPosition: &Position{
+						Line:   4,
+						Column: 9,
+						Offset: 61,
+					},
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 1,
+						Offset: 0,
+					},
+					CommaPosition: &Position{
+						Line:   3,
+						Column: 37,
+						Offset: 51,
+					},
+					TrailingComment: "// trailing",
+				},
+			},
+		},
+		{
+			name:  "trailing commas",
+			input: `{"a": [1,2,3,], "b": {"c": 4,},}`,
+			ptrs:  []string{"/a/2", "/b/c"},
+			opts:  []Option{WithTrailingCommas()},
+			expect: map[string]JSONPointerPosition{
+				"/a/2": {
+					Ptr:   *newJSONPtr([]string{"a", "2"}),
+					Depth: 2,
+					Kind:  KindNumber,
+					Position: Position{
+						Line:   1,
+						Column: 12,
+						Offset: 11,
+					},
+					EndPosition: Position{
+						Line:   1,
+						Column: 12,
+						Offset: 11,
+					},
+					ByteLength: 1,
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 7,
+						Offset: 6,
+					},
+					CommaPosition: &Position{
+						Line:   1,
+						Column: 11,
+						Offset: 10,
+					},
+				},
+				"/b/c": {
+					Ptr:   *newJSONPtr([]string{"b", "c"}),
+					Depth: 2,
+					Kind:  KindNumber,
+					Position: Position{
+						Line:   1,
+						Column: 28,
+						Offset: 27,
+					},
+					EndPosition: Position{
+						Line:   1,
+						Column: 28,
+						Offset: 27,
+					},
+					ByteLength: 1,
+					KeyPosition: &Position{
+						Line:   1,
+						Column: 23,
+						Offset: 22,
+					},
+					KeyEndPosition: &Position{
+						Line:   1,
+						Column: 25,
+						Offset: 24,
+					},
+					ColonPosition: &Position{
+						Line:   1,
+						Column: 26,
+						Offset: 25,
+					},
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 22,
+						Offset: 21,
+					},
+					CommaPosition: &Position{
+						Line:   1,
+						Column: 29,
+						Offset: 28,
+					},
+				},
+			},
+		},
+		{
+			name:  "leading BOM",
+			input: "\uFEFF{\"a\":1}",
+			ptrs:  []string{"/a"},
+			expect: map[string]JSONPointerPosition{
+				"/a": {
+					Ptr:   *newJSONPtr([]string{"a"}),
+					Depth: 1,
+					Kind:  KindNumber,
+					Position: Position{
+						Line:   1,
+						Column: 6,
+						Offset: 8,
+					},
+					EndPosition: Position{
+						Line:   1,
+						Column: 6,
+						Offset: 8,
+					},
+					ByteLength: 1,
+					KeyPosition: &Position{
+						Line:   1,
+						Column: 2,
+						Offset: 4,
+					},
+					KeyEndPosition: &Position{
+						Line:   1,
+						Column: 4,
+						Offset: 6,
+					},
+					ColonPosition: &Position{
+						Line:   1,
+						Column: 5,
+						Offset: 7,
+					},
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 1,
+						Offset: 3,
+					},
+				},
+			},
+		},
+		{
+			name:  "CRLF line endings",
+			input: "{\r\n  \"a\": 1,\r\n  \"b\": {\r\n    \"c\": 2\r\n  }\r\n}",
+			ptrs:  []string{"/b", "/b/c"},
+			expect: map[string]JSONPointerPosition{
+				"/b": {
+					Ptr:   *newJSONPtr([]string{"b"}),
+					Depth: 1,
+					Kind:  KindObject,
+					Position: Position{
+						Line:   3,
+						Column: 8,
+						Offset: 21,
+					},
+					EndPosition: Position{
+						Line:   5,
+						Column: 3,
+						Offset: 38,
+					},
+					ClosePosition: &Position{
+						Line:   5,
+						Column: 3,
+						Offset: 38,
+					},
+					ByteLength: 18,
+					KeyPosition: &Position{
+						Line:   3,
+						Column: 3,
+						Offset: 16,
+					},
+					KeyEndPosition: &Position{
+						Line:   3,
+						Column: 5,
+						Offset: 18,
+					},
+					ColonPosition: &Position{
+						Line:   3,
+						Column: 6,
+						Offset: 19,
+					},
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 1,
+						Offset: 0,
+					},
+					CommaPosition: &Position{
+						Line:   2,
+						Column: 9,
+						Offset: 11,
+					},
+				},
+				"/b/c": {
+					Ptr:   *newJSONPtr([]string{"b", "c"}),
+					Depth: 2,
+					Kind:  KindNumber,
+					Position: Position{
+						Line:   4,
+						Column: 10,
+						Offset: 33,
+					},
+					EndPosition: Position{
+						Line:   4,
+						Column: 10,
+						Offset: 33,
+					},
+					ByteLength: 1,
+					KeyPosition: &Position{
+						Line:   4,
+						Column: 5,
+						Offset: 28,
+					},
+					KeyEndPosition: &Position{
+						Line:   4,
+						Column: 7,
+						Offset: 30,
+					},
+					ColonPosition: &Position{
+						Line:   4,
+						Column: 8,
+						Offset: 31,
+					},
+					ParentPosition: &Position{
+						Line:   3,
+						Column: 8,
+						Offset: 21,
+					},
+				},
+			},
+		},
+		{
+			name:  "CR line endings",
+			input: "[\r1,\r2]",
+			ptrs:  []string{"/1"},
+			expect: map[string]JSONPointerPosition{
+				"/1": {
+					Ptr:   *newJSONPtr([]string{"1"}),
+					Depth: 1,
+					Kind:  KindNumber,
+					Position: Position{
+						Line:   3,
+						Column: 1,
+						Offset: 5,
+					},
+					EndPosition: Position{
+						Line:   3,
+						Column: 1,
+						Offset: 5,
+					},
+					ByteLength: 1,
+					ParentPosition: &Position{
+						Line:   1,
+						Column: 1,
+						Offset: 0,
+					},
+					CommaPosition: &Position{
+						Line:   2,
+						Column: 2,
+						Offset: 3,
+					},
+				},
+			},
+		},
+		{
+			name:  "root",
+			input: `{"a":1}`,
+			ptrs:  []string{""},
+			expect: map[string]JSONPointerPosition{
+				"": {
+					Position: Position{
+						Line:   1,
+						Column: 1,
+						Offset: 0,
+					},
+					EndPosition: Position{
+						Line:   1,
+						Column: 7,
+						Offset: 6,
+					},
+					ClosePosition: &Position{
+						Line:   1,
+						Column: 7,
+						Offset: 6,
+					},
+					ByteLength: 7,
+					Kind:       KindObject,
+				},
+			},
+		},
+		{
+			name:  "root scalar with leading whitespace",
+			input: "\n  \"foo\"",
+			ptrs:  []string{""},
+			expect: map[string]JSONPointerPosition{
+				"": {
+					Position: Position{
+						Line:   2,
+						Column: 3,
+						Offset: 3,
+					},
+					EndPosition: Position{
+						Line:   2,
+						Column: 7,
+						Offset: 7,
+					},
+					ByteLength: 5,
+					Kind:       KindString,
+				},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var ptrs []jsonpointer.Pointer
+			for _, v := range tt.ptrs {
+				ptr, err := jsonpointer.New(v)
+				require.NoError(t, err)
+				ptrs = append(ptrs, ptr)
+			}
+			out, err := GetPositions(tt.input, ptrs, tt.opts...)
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, out)
+		})
+	}
+}
+
+func TestGetPosition(t *testing.T) {
+	input := `{"a": {"b": [1, 2]}}`
+
+	ptr, err := jsonpointer.New("/a/b/1")
+	require.NoError(t, err)
+	pos, ok, err := GetPosition(input, ptr)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, JSONPointerPosition{
+		Ptr:            ptr,
+		Depth:          3,
+		Kind:           KindNumber,
+		Position:       Position{Line: 1, Column: 17, Offset: 16},
+		EndPosition:    Position{Line: 1, Column: 17, Offset: 16},
+		ByteLength:     1,
+		ParentPosition: &Position{Line: 1, Column: 13, Offset: 12},
+		CommaPosition:  &Position{Line: 1, Column: 15, Offset: 14},
+	}, pos)
+
+	ptr, err = jsonpointer.New("/a/c")
+	require.NoError(t, err)
+	_, ok, err = GetPosition(input, ptr)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestGetPositionsSorted(t *testing.T) {
+	input := `{
+  "a": 1, "b": 2,
+  "c": [3, 4]
+}`
+	var ptrs []jsonpointer.Pointer
+	for _, v := range []string{"/c/1", "/b", "/c", "/a", "/c/0"} {
+		ptr, err := jsonpointer.New(v)
+		require.NoError(t, err)
+		ptrs = append(ptrs, ptr)
+	}
+	out, err := GetPositionsSorted(input, ptrs)
+	require.NoError(t, err)
+	var got []string
+	for _, pos := range out {
+		got = append(got, pos.Ptr.String())
+	}
+	require.Equal(t, []string{"/a", "/b", "/c", "/c/0", "/c/1"}, got)
+}
+
+func TestGetAllPositions(t *testing.T) {
+	input := `{
+  "a": [1, {"b": null}],
+  "c": "d"
+}`
+	out, err := GetAllPositions(input)
+	require.NoError(t, err)
+	require.Equal(t, map[string]JSONPointerPosition{
+		"": {
+			Position:      Position{Line: 1, Column: 1, Offset: 0},
+			EndPosition:   Position{Line: 4, Column: 1, Offset: 38},
+			ClosePosition: &Position{Line: 4, Column: 1, Offset: 38},
+			ByteLength:    39,
+			Kind:          KindObject,
+		},
+		"/a": {
+			Ptr:            *newJSONPtr([]string{"a"}),
+			Depth:          1,
+			Kind:           KindArray,
+			Position:       Position{Line: 2, Column: 8, Offset: 9},
+			EndPosition:    Position{Line: 2, Column: 23, Offset: 24},
+			ClosePosition:  &Position{Line: 2, Column: 23, Offset: 24},
+			ByteLength:     16,
+			KeyPosition:    &Position{Line: 2, Column: 3, Offset: 4},
+			KeyEndPosition: &Position{Line: 2, Column: 5, Offset: 6},
+			ColonPosition:  &Position{Line: 2, Column: 6, Offset: 7},
+			ParentPosition: &Position{Line: 1, Column: 1, Offset: 0},
+			CommaPosition:  &Position{Line: 2, Column: 24, Offset: 25},
+		},
+		"/a/0": {
+			Ptr:            *newJSONPtr([]string{"a", "0"}),
+			Depth:          2,
+			Kind:           KindNumber,
+			Position:       Position{Line: 2, Column: 9, Offset: 10},
+			EndPosition:    Position{Line: 2, Column: 9, Offset: 10},
+			ByteLength:     1,
+			ParentPosition: &Position{Line: 2, Column: 8, Offset: 9},
+			CommaPosition:  &Position{Line: 2, Column: 10, Offset: 11},
+		},
+		"/a/1": {
+			Ptr:            *newJSONPtr([]string{"a", "1"}),
+			Depth:          2,
+			Kind:           KindObject,
+			Position:       Position{Line: 2, Column: 12, Offset: 13},
+			EndPosition:    Position{Line: 2, Column: 22, Offset: 23},
+			ClosePosition:  &Position{Line: 2, Column: 22, Offset: 23},
+			ByteLength:     11,
+			ParentPosition: &Position{Line: 2, Column: 8, Offset: 9},
+			CommaPosition:  &Position{Line: 2, Column: 10, Offset: 11},
+		},
+		"/a/1/b": {
+			Ptr:            *newJSONPtr([]string{"a", "1", "b"}),
+			Depth:          3,
+			Kind:           KindNull,
+			Position:       Position{Line: 2, Column: 18, Offset: 19},
+			EndPosition:    Position{Line: 2, Column: 21, Offset: 22},
+			ByteLength:     4,
+			KeyPosition:    &Position{Line: 2, Column: 13, Offset: 14},
+			KeyEndPosition: &Position{Line: 2, Column: 15, Offset: 16},
+			ColonPosition:  &Position{Line: 2, Column: 16, Offset: 17},
+			ParentPosition: &Position{Line: 2, Column: 12, Offset: 13},
+		},
+		"/c": {
+			Ptr:            *newJSONPtr([]string{"c"}),
+			Depth:          1,
+			Kind:           KindString,
+			Position:       Position{Line: 3, Column: 8, Offset: 34},
+			EndPosition:    Position{Line: 3, Column: 10, Offset: 36},
+			ByteLength:     3,
+			KeyPosition:    &Position{Line: 3, Column: 3, Offset: 29},
+			KeyEndPosition: &Position{Line: 3, Column: 5, Offset: 31},
+			ColonPosition:  &Position{Line: 3, Column: 6, Offset: 32},
+			ParentPosition: &Position{Line: 1, Column: 1, Offset: 0},
+			CommaPosition:  &Position{Line: 2, Column: 24, Offset: 25},
+		},
+	}, out)
+}
+
+func TestGetAllPositionsDeep(t *testing.T) {
+	const depth = 2000
+	input := strings.Repeat("[", depth) + strings.Repeat("]", depth)
+	out, err := GetAllPositions(input)
+	require.NoError(t, err)
+	require.Len(t, out, depth)
+	ptr := strings.Repeat("/0", depth-1)
+	require.Equal(t, depth-1, out[ptr].Depth)
+	require.Equal(t, Position{Line: 1, Column: depth, Offset: depth - 1}, out[ptr].Position)
+}
+
+func BenchmarkGetAllPositionsDeep(b *testing.B) {
+	input := strings.Repeat("[", 5000) + strings.Repeat("]", 5000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := GetAllPositions(input); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestGetPositionsWildcard(t *testing.T) {
+	input := `{
+  "servers": [
+    {"name": "a", "port": 80},
+    {"name": "b"},
+    {"name": "c", "port": 443}
+  ],
+  "x": {"port": 1}
+}`
+	cases := []struct {
+		name   string
+		ptrs   []string
+		expect []string
+	}{
+		{
+			name:   "array elements",
+			ptrs:   []string{"/servers/*/port"},
+			expect: []string{"/servers/0/port", "/servers/2/port"},
+		},
+		{
+			name:   "object members",
+			ptrs:   []string{"/*/port"},
+			expect: []string{"/x/port"},
+		},
+		{
+			name:   "mixed with concrete pointers",
+			ptrs:   []string{"/servers/1/name", "/servers/*/port", "/*/1"},
+			expect: []string{"/servers/0/port", "/servers/1", "/servers/1/name", "/servers/2/port"},
+		},
+		{
+			name:   "multiple wildcards",
+			ptrs:   []string{"/*/*/name"},
+			expect: []string{"/servers/0/name", "/servers/1/name", "/servers/2/name"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var ptrs []jsonpointer.Pointer
+			for _, v := range tt.ptrs {
+				ptr, err := jsonpointer.New(v)
+				require.NoError(t, err)
+				ptrs = append(ptrs, ptr)
+			}
+			out, err := GetPositionsSorted(input, ptrs, WithWildcards())
+			require.NoError(t, err)
+			var got []string
+			for _, pos := range out {
+				got = append(got, pos.Ptr.String())
+			}
+			require.Equal(t, tt.expect, got)
+		})
+	}
+
+	ptr, err := jsonpointer.New("/servers/*/port")
+	require.NoError(t, err)
+	out, err := GetPositions(input, []jsonpointer.Pointer{ptr}, WithWildcards())
+	require.NoError(t, err)
+	require.Equal(t, Position{Line: 5, Column: 27, Offset: 93}, out["/servers/2/port"].Position)
+}
+
+func TestGetPositionsRecursiveWildcard(t *testing.T) {
+	input := `{"name": 1, "a": {"name": 2, "b": [{"name": 3}, {"c": {"name": 4}}]}, "d": [5]}`
+	cases := []struct {
+		name   string
+		ptrs   []string
+		expect []string
+	}{
+		{
+			name:   "any depth",
+			ptrs:   []string{"/**/name"},
+			expect: []string{"/name", "/a/name", "/a/b/0/name", "/a/b/1/c/name"},
+		},
+		{
+			name:   "under a prefix",
+			ptrs:   []string{"/a/**/name"},
+			expect: []string{"/a/name", "/a/b/0/name", "/a/b/1/c/name"},
+		},
+		{
+			name:   "followed by a wildcard",
+			ptrs:   []string{"/a/**/*/name"},
+			expect: []string{"/a/b/0/name", "/a/b/1/c/name"},
+		},
+		{
+			name:   "array index",
+			ptrs:   []string{"/**/0"},
+			expect: []string{"/a/b/0", "/d/0"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var ptrs []jsonpointer.Pointer
+			for _, v := range tt.ptrs {
+				ptr, err := jsonpointer.New(v)
+				require.NoError(t, err)
+				ptrs = append(ptrs, ptr)
+			}
+			out, err := GetPositionsSorted(input, ptrs, WithWildcards())
+			require.NoError(t, err)
+			var got []string
+			for _, pos := range out {
+				got = append(got, pos.Ptr.String())
+			}
+			require.Equal(t, tt.expect, got)
+		})
+	}
+}
+
+func TestGetPositionsLiteralWildcardKeys(t *testing.T) {
+	input := `{"*": 1, "**": {"a": 2}, "b": 3}`
+	ptrs := []jsonpointer.Pointer{mustPointer("/*"), mustPointer("/**/a")}
+
+	// The keys "*" and "**" are literal without WithWildcards
+	out, err := GetPositions(input, ptrs)
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	require.Equal(t, Position{Line: 1, Column: 7, Offset: 6}, out["/*"].Position)
+	require.Equal(t, Position{Line: 1, Column: 22, Offset: 21}, out["/**/a"].Position)
+
+	out, err = GetPositions(input, ptrs, WithWildcards())
+	require.NoError(t, err)
+	var got []string
+	for k := range out {
+		got = append(got, k)
+	}
+	sort.Strings(got)
+	require.Equal(t, []string{"/*", "/**", "/**/a", "/b"}, got)
+
+	// The compiled pointers are the same
+	c := Compile(ptrs)
+	out, err = c.Positions(input)
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	out, err = c.Positions(input, WithWildcards())
+	require.NoError(t, err)
+	require.Len(t, out, 4)
+}
+
+func TestGetPositionsMaxMatches(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		ptrs   []string
+		n      int
+		expect []string
+	}{
+		{
+			name:   "wildcard",
+			input:  `[1, 2, 3, 4]`,
+			ptrs:   []string{"/*"},
+			n:      2,
+			expect: []string{"/0", "/1"},
+		},
+		{
+			name:   "recursive wildcard",
+			input:  `{"a": {"b": 1}, "c": 2}`,
+			ptrs:   []string{"/**"},
+			n:      3,
+			expect: []string{"", "/a", "/a/b"},
+		},
+		{
+			name:   "nested",
+			input:  `[{"name": "x"}, {"name": "y"}, {"name": "z"}]`,
+			ptrs:   []string{"/*/name"},
+			n:      2,
+			expect: []string{"/0/name", "/1/name"},
+		},
+		{
+			name:   "across pointers",
+			input:  `{"b": [3, 4], "a": [1, 2]}`,
+			ptrs:   []string{"/a/*", "/b/*"},
+			n:      3,
+			expect: []string{"/b/0", "/b/1", "/a/0"},
+		},
+		{
+			name:   "pointers without wildcards",
+			input:  `{"items": [1, 2, 3], "last": {"x": true}}`,
+			ptrs:   []string{"/items/*", "/last/x", "/items/2"},
+			n:      1,
+			expect: []string{"/items/0", "/items/2", "/last/x"},
+		},
+		{
+			name:   "fewer matches",
+			input:  `[1, 2]`,
+			ptrs:   []string{"/*"},
+			n:      3,
+			expect: []string{"/0", "/1"},
+		},
+		{
+			name:   "no limit",
+			input:  `[1, 2]`,
+			ptrs:   []string{"/*"},
+			n:      0,
+			expect: []string{"/0", "/1"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var ptrs []jsonpointer.Pointer
+			for _, v := range tt.ptrs {
+				ptrs = append(ptrs, mustPointer(v))
+			}
+			out, err := GetPositionsSorted(tt.input, ptrs, WithMaxMatches(tt.n), WithWildcards())
+			require.NoError(t, err)
+			var got []string
+			for _, pos := range out {
+				got = append(got, pos.Ptr.String())
+			}
+			require.Equal(t, tt.expect, got)
+		})
+	}
+
+	// With WithAllowTrailing, the walk stops once the limit is reached, before the malformed rest of the document
+	input := `[[1, 2, 3, x`
+	ptrs := []jsonpointer.Pointer{mustPointer("/0/*")}
+	_, err := GetPositions(input, ptrs, WithAllowTrailing(), WithWildcards())
+	require.Error(t, err)
+	_, err = GetPositions(input, ptrs, WithMaxMatches(2), WithWildcards())
+	require.Error(t, err)
+	for _, r := range []func() io.Reader{
+		func() io.Reader { return strings.NewReader(input) },
+		func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+	} {
+		out, err := GetPositionsReader(r(), ptrs, WithMaxMatches(2), WithAllowTrailing(), WithWildcards())
+		require.NoError(t, err)
+		require.Equal(t, []string{"/0/0", "/0/1"}, sortedKeys(out))
+		require.Equal(t, Position{Line: 1, Column: 6, Offset: 5}, out["/0/1"].Position)
+	}
+}
+
+func TestGetPositionsContext(t *testing.T) {
+	input := "[" + strings.Repeat(`{"a": [1, 2, 3]}, `, 100000) + "0]"
+	ptr, err := jsonpointer.New("/100000")
+	require.NoError(t, err)
+
+	out, err := GetPositionsContext(context.Background(), input, []jsonpointer.Pointer{ptr})
+	require.NoError(t, err)
+	require.Contains(t, out, "/100000")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = GetPositionsContext(ctx, input, []jsonpointer.Pointer{ptr})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGetPositionsEarlyStop(t *testing.T) {
+	// The document is malformed after the pointed values, which is not reached as the walk stops early.
+	input := `{"a": {"b": 1, "c": [2, 3]}, "d": ]`
+	var ptrs []jsonpointer.Pointer
+	for _, v := range []string{"/a/c/0", "/a/b"} {
+		ptr, err := jsonpointer.New(v)
+		require.NoError(t, err)
+		ptrs = append(ptrs, ptr)
+	}
+	out, err := GetPositions(input, ptrs, WithFirstDuplicateKey(), WithAllowTrailing())
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	require.Equal(t, Position{Line: 1, Column: 22, Offset: 21}, out["/a/c/0"].Position)
+
+	// Without WithAllowTrailing, the rest of the document is still validated
+	_, err = GetPositions(input, ptrs, WithFirstDuplicateKey())
+	require.Error(t, err)
+
+	// Without WithFirstDuplicateKey, the object members are final once the root object ends, as "a" might be repeated
+	_, err = GetPositions(input, ptrs, WithAllowTrailing())
+	require.Error(t, err)
+	out, err = GetPositions(`[{"a": {"b": 1, "c": [2, 3]}}, ]`, []jsonpointer.Pointer{mustPointer("/0/a/c/0")}, WithAllowTrailing())
+	require.NoError(t, err)
+	require.Equal(t, Position{Line: 1, Column: 23, Offset: 22}, out["/0/a/c/0"].Position)
+
+	// The root value can only be found after walking through the whole document.
+	ptr, err := jsonpointer.New("")
+	require.NoError(t, err)
+	_, err = GetPositions(input, append(ptrs, ptr), WithFirstDuplicateKey(), WithAllowTrailing())
+	require.Error(t, err)
+}
+
+func TestGetPositionsRaw(t *testing.T) {
+	input := `{
+  "a": {"b": "x y", "c": [1.5e3, true]},
+  "d": null
+}`
+	var ptrs []jsonpointer.Pointer
+	for _, v := range []string{"/a", "/a/b", "/a/c/0", "/d"} {
+		ptr, err := jsonpointer.New(v)
+		require.NoError(t, err)
+		ptrs = append(ptrs, ptr)
+	}
+	expect := map[string]string{
+		"/a":     `{"b": "x y", "c": [1.5e3, true]}`,
+		"/a/b":   `"x y"`,
+		"/a/c/0": `1.5e3`,
+		"/d":     `null`,
+	}
+	for _, r := range []io.Reader{strings.NewReader(input), iotest.OneByteReader(strings.NewReader(input))} {
+		out, err := GetPositionsReader(r, ptrs, WithRaw())
+		require.NoError(t, err)
+		got := map[string]string{}
+		for k, v := range out {
+			got[k] = v.Raw
+		}
+		require.Equal(t, expect, got)
+	}
+
+	out, err := GetPositions(input, ptrs)
+	require.NoError(t, err)
+	require.Empty(t, out["/a"].Raw)
+
+	// The values matched by wildcards are captured as well
+	out, err = GetPositions(input, []jsonpointer.Pointer{mustPointer("/a/c/*"), mustPointer("/**/b")}, WithRaw(), WithWildcards())
+	require.NoError(t, err)
+	require.Equal(t, `1.5e3`, out["/a/c/0"].Raw)
+	require.Equal(t, `true`, out["/a/c/1"].Raw)
+	require.Equal(t, `"x y"`, out["/a/b"].Raw)
+}
+
+func TestGetPositionsDuplicateKeys(t *testing.T) {
+	input := `{"a": {"b": 1}, "c": 2, "a": {"b": 3}}`
+	var ptrs []jsonpointer.Pointer
+	for _, v := range []string{"/a", "/a/b", "/c"} {
+		ptr, err := jsonpointer.New(v)
+		require.NoError(t, err)
+		ptrs = append(ptrs, ptr)
+	}
+
+	// The last occurrence is reported as encoding/json decodes
+	for _, r := range []func() io.Reader{
+		func() io.Reader { return strings.NewReader(input) },
+		func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+	} {
+		out, err := GetPositionsReader(r(), ptrs)
+		require.NoError(t, err)
+		require.Equal(t, int64(29), out["/a"].Offset)
+		require.Equal(t, int64(35), out["/a/b"].Offset)
+		require.Nil(t, out["/a"].DuplicatePositions)
+	}
+	out, err := GetPositions(`{"a":1,"a":2}`, []jsonpointer.Pointer{mustPointer("/a")})
+	require.NoError(t, err)
+	require.Equal(t, Position{Line: 1, Column: 12, Offset: 11}, out["/a"].Position)
+	all, err := GetAllPositions(input)
+	require.NoError(t, err)
+	require.Equal(t, int64(35), all["/a/b"].Offset)
+
+	// The members of the first occurrence that the last one doesn't have are not reported
+	out, err = GetPositions(`{"a": {"b": 1}, "a": {}}`, ptrs, WithReportMissing())
+	require.NoError(t, err)
+	require.True(t, out["/a/b"].Missing)
+
+	out, err = GetPositions(input, ptrs, WithFirstDuplicateKey())
+	require.NoError(t, err)
+	require.Equal(t, int64(6), out["/a"].Offset)
+	require.Equal(t, int64(12), out["/a/b"].Offset)
+	require.Nil(t, out["/a"].DuplicatePositions)
+
+	for _, opts := range [][]Option{{WithDuplicateKeys()}, {WithDuplicateKeys(), WithFirstDuplicateKey()}} {
+		out, err = GetPositions(input, ptrs, append(opts, WithRaw())...)
+		require.NoError(t, err)
+		if len(opts) == 1 {
+			require.Equal(t, int64(29), out["/a"].Offset)
+			require.Equal(t, `{"b": 3}`, out["/a"].Raw)
+		} else {
+			require.Equal(t, int64(6), out["/a"].Offset)
+			require.Equal(t, `{"b": 1}`, out["/a"].Raw)
+		}
+		require.Equal(t, []Position{
+			{Line: 1, Column: 7, Offset: 6},
+			{Line: 1, Column: 30, Offset: 29},
+		}, out["/a"].DuplicatePositions)
+		require.Equal(t, []Position{
+			{Line: 1, Column: 13, Offset: 12},
+			{Line: 1, Column: 36, Offset: 35},
+		}, out["/a/b"].DuplicatePositions)
+		require.Nil(t, out["/c"].DuplicatePositions)
+	}
+}
+
+func TestGetPositionsStrictDuplicateKeys(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		ptrs   []string
+		expect *DuplicateKeyError
+	}{
+		{
+			name:  "no duplicate",
+			input: `{"a": {"x": 1, "y": 2}, "b": [{"x": 1}, {"x": 2}], "A": 3}`,
+			ptrs:  []string{"/a/x"},
+		},
+		{
+			name:   "walked object",
+			input:  `{"a": 1, "b": 2, "a": 3}`,
+			ptrs:   []string{"/b"},
+			expect: &DuplicateKeyError{Key: "a", Position: Position{Line: 1, Column: 18, Offset: 17}},
+		},
+		{
+			name:   "drained object after all pointers are found",
+			input:  "{\"a\": 1,\n \"b\": [{\"c\": {}, \"d\": [1], \"c\": null}]}",
+			ptrs:   []string{"/a"},
+			expect: &DuplicateKeyError{Key: "c", Position: Position{Line: 2, Column: 28, Offset: 36}},
+		},
+		{
+			name:   "escaped key",
+			input:  `{"é": 1, "\u00e9": 2}`,
+			ptrs:   []string{""},
+			expect: &DuplicateKeyError{Key: "é", Position: Position{Line: 1, Column: 10, Offset: 10}},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var ptrs []jsonpointer.Pointer
+			for _, ptr := range tt.ptrs {
+				ptrs = append(ptrs, mustPointer(ptr))
+			}
+			for _, r := range []func() io.Reader{
+				func() io.Reader { return strings.NewReader(tt.input) },
+				func() io.Reader { return iotest.OneByteReader(strings.NewReader(tt.input)) },
+			} {
+				_, err := GetPositionsReader(r(), ptrs, WithStrictDuplicateKeys())
+				if tt.expect == nil {
+					require.NoError(t, err)
+					continue
+				}
+				var derr *DuplicateKeyError
+				require.ErrorAs(t, err, &derr)
+				require.Equal(t, tt.expect, derr)
+			}
+			_, err := GetAllPositions(tt.input, WithStrictDuplicateKeys())
+			if tt.expect == nil {
+				require.NoError(t, err)
+			} else {
+				require.Equal(t, tt.expect, err)
+			}
+			// Without the option, the first occurrence wins
+			_, err = GetPositions(tt.input, ptrs)
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestGetPositionsColon(t *testing.T) {
+	input := "{\"a\"  \n\t:  {\"b\" /* : */ : [1]}, \"c\":2}"
+	ptrs := []jsonpointer.Pointer{mustPointer("/a"), mustPointer("/a/b"), mustPointer("/a/b/0"), mustPointer("/c")}
+	expect := map[string]*Position{
+		"/a":     {Line: 2, Column: 2, Offset: 8},
+		"/a/b":   {Line: 2, Column: 18, Offset: 24},
+		"/a/b/0": nil,
+		"/c":     {Line: 2, Column: 29, Offset: 35},
+	}
+
+	// Read byte by byte to ensure the bytes between the key and the colon are kept
+	out, err := GetPositionsReader(iotest.OneByteReader(strings.NewReader(input)), ptrs, WithComments())
+	require.NoError(t, err)
+	for k, v := range expect {
+		require.Equal(t, v, out[k].ColonPosition, k)
+	}
+}
+
+func TestGetPositionsLineCommentBreak(t *testing.T) {
+	// A line comment ends at any line break
+	cases := []struct {
+		input  string
+		expect Position
+	}{
+		{input: "// c\n{\"a\":1}", expect: Position{Line: 2, Column: 6, Offset: 10}},
+		{input: "// c\r\n{\"a\":1}", expect: Position{Line: 2, Column: 6, Offset: 11}},
+		{input: "// c\r{\"a\":1}", expect: Position{Line: 2, Column: 6, Offset: 10}},
+	}
+	for _, tt := range cases {
+		for _, r := range []io.Reader{strings.NewReader(tt.input), iotest.OneByteReader(strings.NewReader(tt.input))} {
+			out, err := GetPositionsReader(r, []jsonpointer.Pointer{mustPointer("/a")}, WithComments())
+			require.NoError(t, err, tt.input)
+			require.Equal(t, tt.expect, out["/a"].Position, tt.input)
+		}
+	}
+
+	// The CRs are counted as line breaks when associating the comments
+	input := "{\r  // x\r  \"a\": 1, // y\r\r  // z\r\r  \"b\": 2\r}"
+	out, err := GetPositions(input, []jsonpointer.Pointer{mustPointer("/a"), mustPointer("/b")}, WithComments())
+	require.NoError(t, err)
+	require.Equal(t, Position{Line: 3, Column: 8, Offset: 16}, out["/a"].Position)
+	require.Equal(t, []string{"// x"}, out["/a"].LeadingComments)
+	require.Equal(t, "// y", out["/a"].TrailingComment)
+	require.Equal(t, Position{Line: 7, Column: 8, Offset: 40}, out["/b"].Position)
+	require.Nil(t, out["/b"].LeadingComments)
+}
+
+func TestGetPositionsUnterminatedComment(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect Position
+	}{
+		{input: `{"a":1} /* unterminated`, expect: Position{Line: 1, Column: 9, Offset: 8}},
+		{input: `{"a":1} /* x *`, expect: Position{Line: 1, Column: 9, Offset: 8}},
+		{input: "{\"a\":1 /* x\n}", expect: Position{Line: 1, Column: 8, Offset: 7}},
+	}
+	for _, tt := range cases {
+		for _, opts := range [][]Option{{WithComments()}, {WithJSON5()}} {
+			for _, r := range []io.Reader{strings.NewReader(tt.input), iotest.OneByteReader(strings.NewReader(tt.input))} {
+				_, err := GetPositionsReader(r, []jsonpointer.Pointer{mustPointer("/a")}, opts...)
+				var perr *ParseError
+				require.ErrorAs(t, err, &perr, tt.input)
+				require.Equal(t, tt.expect, perr.Position, tt.input)
+			}
+		}
+	}
+}
+
+func TestGetPositionsParent(t *testing.T) {
+	input := "{\n  \"a\": {\n    \"b\": [true]\n  }\n}"
+	out, err := GetPositions(input, []jsonpointer.Pointer{mustPointer(""), mustPointer("/a"), mustPointer("/a/b"), mustPointer("/a/b/0")})
+	require.NoError(t, err)
+	require.Nil(t, out[""].ParentPosition)
+	require.Equal(t, &Position{Line: 1, Column: 1, Offset: 0}, out["/a"].ParentPosition)
+	require.Equal(t, &Position{Line: 2, Column: 8, Offset: 9}, out["/a/b"].ParentPosition)
+	require.Equal(t, &Position{Line: 3, Column: 10, Offset: 20}, out["/a/b/0"].ParentPosition)
+}
+
+func TestGetPositionsKind(t *testing.T) {
+	input := `{"s": "x", "n": -1.5e3, "t": true, "f": false, "z": null, "o": {}, "a": [1]}`
+	expect := map[string]Kind{
+		"/s": KindString, "/n": KindNumber, "/t": KindBoolean, "/f": KindBoolean, "/z": KindNull, "/o": KindObject,
+		"/a": KindArray, "/a/0": KindNumber, "/a/-": KindUnknown, "/x": KindUnknown,
+	}
+	var ptrs []jsonpointer.Pointer
+	for k := range expect {
+		ptrs = append(ptrs, mustPointer(k))
+	}
+	out, err := GetPositions(input, ptrs, WithReportMissing())
+	require.NoError(t, err)
+	for k, kind := range expect {
+		require.Equal(t, kind, out[k].Kind, k)
+	}
+
+	// The kind matches the type of the decoded value
+	values, err := GetPositionsWithValues(input, []jsonpointer.Pointer{mustPointer("/n")})
+	require.NoError(t, err)
+	require.IsType(t, json.Number(""), values["/n"].Value)
+	require.Equal(t, KindNumber, values["/n"].Kind)
+	require.Equal(t, "number", KindNumber.String())
+}
+
+func TestKindText(t *testing.T) {
+	for k := KindUnknown; k <= KindArray; k++ {
+		text, err := k.MarshalText()
+		require.NoError(t, err)
+		require.Equal(t, k.String(), string(text))
+		var got Kind
+		require.NoError(t, got.UnmarshalText(text))
+		require.Equal(t, k, got)
+	}
+	for r := MissingUnknown; r <= MissingNotContainer; r++ {
+		text, err := r.MarshalText()
+		require.NoError(t, err)
+		require.Equal(t, r.String(), string(text))
+		var got MissingReason
+		require.NoError(t, got.UnmarshalText(text))
+		require.Equal(t, r, got)
+	}
+
+	// The names are used in JSON, such as the object keys
+	b, err := json.Marshal(map[Kind]MissingReason{KindArray: MissingIndexOutOfRange})
+	require.NoError(t, err)
+	require.Equal(t, `{"array":"index out of range"}`, string(b))
+	var m map[Kind]MissingReason
+	require.NoError(t, json.Unmarshal(b, &m))
+	require.Equal(t, map[Kind]MissingReason{KindArray: MissingIndexOutOfRange}, m)
+
+	var k Kind
+	require.Error(t, k.UnmarshalText([]byte("int")))
+	var r MissingReason
+	require.Error(t, r.UnmarshalText([]byte("")))
+}
+
+func TestGetPositionsEmptyKeys(t *testing.T) {
+	input := "{\"\": {\"\": 1, \"x\": [{\"\": 2}]},\n \"a\": {\"\": {\"\": null}, \"b\": 3}}"
+	cases := []struct {
+		ptr    string
+		depth  int
+		pos    Position
+		keyPos Position
+	}{
+		{ptr: "/", depth: 1, pos: Position{Line: 1, Column: 6, Offset: 5}, keyPos: Position{Line: 1, Column: 2, Offset: 1}},
+		{ptr: "//", depth: 2, pos: Position{Line: 1, Column: 11, Offset: 10}, keyPos: Position{Line: 1, Column: 7, Offset: 6}},
+		{ptr: "//x/0/", depth: 4, pos: Position{Line: 1, Column: 25, Offset: 24}, keyPos: Position{Line: 1, Column: 21, Offset: 20}},
+		{ptr: "/a/", depth: 2, pos: Position{Line: 2, Column: 12, Offset: 41}, keyPos: Position{Line: 2, Column: 8, Offset: 37}},
+		{ptr: "/a//", depth: 3, pos: Position{Line: 2, Column: 17, Offset: 46}, keyPos: Position{Line: 2, Column: 13, Offset: 42}},
+	}
+	var ptrs []jsonpointer.Pointer
+	for _, tt := range cases {
+		ptrs = append(ptrs, mustPointer(tt.ptr))
+	}
+	out, err := GetPositions(input, ptrs)
+	require.NoError(t, err)
+	require.Len(t, out, len(cases))
+	for _, tt := range cases {
+		pos := out[tt.ptr]
+		require.Equal(t, tt.depth, pos.Depth, tt.ptr)
+		require.Equal(t, tt.pos, pos.Position, tt.ptr)
+		require.Equal(t, tt.keyPos, *pos.KeyPosition, tt.ptr)
+	}
+
+	// Each pointer is found on its own, without the others creating the intermediate nodes
+	for _, tt := range cases {
+		pos, ok, err := GetPosition(input, mustPointer(tt.ptr))
+		require.NoError(t, err)
+		require.True(t, ok, tt.ptr)
+		require.Equal(t, out[tt.ptr], pos, tt.ptr)
+	}
+
+	all, err := GetAllPositions(input)
+	require.NoError(t, err)
+	for _, tt := range cases {
+		require.Equal(t, out[tt.ptr], all[tt.ptr], tt.ptr)
+	}
+	require.Equal(t, Position{Line: 1, Column: 1, Offset: 0}, all[""].Position)
+}
+
+func TestGetPositionsRuneLength(t *testing.T) {
+	input := `{"a": "café", "b": ["😀", 1]}`
+	ptrs := []jsonpointer.Pointer{mustPointer("/a"), mustPointer("/b"), mustPointer("/b/0")}
+
+	out, err := GetPositions(input, ptrs)
+	require.NoError(t, err)
+	require.Equal(t, 0, out["/a"].RuneLength)
+
+	out, err = GetPositions(input, ptrs, WithRuneLength())
+	require.NoError(t, err)
+	require.Equal(t, 7, out["/a"].ByteLength)
+	require.Equal(t, 6, out["/a"].RuneLength)
+	require.Equal(t, 11, out["/b"].ByteLength)
+	require.Equal(t, 8, out["/b"].RuneLength)
+	require.Equal(t, 6, out["/b/0"].ByteLength)
+	require.Equal(t, 3, out["/b/0"].RuneLength)
+}
+
+func TestGetPositionsLineText(t *testing.T) {
+	input := "{\r\n  \"a\": [1,\r  2],\n  \"b\": \"x\" } // end"
+	ptrs := []jsonpointer.Pointer{mustPointer(""), mustPointer("/a"), mustPointer("/a/1"), mustPointer("/b"), mustPointer("/c")}
+	expect := map[string]string{
+		"":     "{",
+		"/a":   `  "a": [1,`,
+		"/a/1": "  2],",
+		"/b":   `  "b": "x" } // end`,
+		"/c":   "",
+	}
+
+	out, err := GetPositions(input, ptrs, WithReportMissing(), WithComments())
+	require.NoError(t, err)
+	require.Empty(t, out["/a"].LineText)
+
+	for _, r := range []func() io.Reader{
+		func() io.Reader { return strings.NewReader(input) },
+		func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+	} {
+		out, err := GetPositionsReader(r(), ptrs, WithReportMissing(), WithLineText(), WithComments())
+		require.NoError(t, err)
+		for k, text := range expect {
+			require.Equal(t, text, out[k].LineText, k)
+		}
+	}
+	out, err = GetPositions(input, ptrs, WithReportMissing(), WithLineText(), WithComments())
+	require.NoError(t, err)
+	for k, text := range expect {
+		require.Equal(t, text, out[k].LineText, k)
+	}
+}
+
+func TestGetPositionsUnicodeLineTerminators(t *testing.T) {
+	input := "{\"a\": \"x\u2028y\", \"b\": [\"\u2029\", 1]}"
+	ptrs := []jsonpointer.Pointer{mustPointer("/b"), mustPointer("/b/1")}
+
+	// The separators within the strings occupy a column by default
+	out, err := GetPositions(input, ptrs)
+	require.NoError(t, err)
+	require.Equal(t, Position{Line: 1, Column: 14, Offset: 15}, *out["/b"].KeyPosition)
+	require.Equal(t, Position{Line: 1, Column: 25, Offset: 28}, out["/b/1"].Position)
+
+	for _, r := range []func() io.Reader{
+		func() io.Reader { return strings.NewReader(input) },
+		func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+	} {
+		out, err := GetPositionsReader(r(), ptrs, WithUnicodeLineTerminators(), WithLineText())
+		require.NoError(t, err)
+		require.Equal(t, Position{Line: 2, Column: 5, Offset: 15}, *out["/b"].KeyPosition)
+		require.Equal(t, Position{Line: 3, Column: 4, Offset: 28}, out["/b/1"].Position)
+		require.Equal(t, `y", "b": ["`, out["/b"].LineText)
+	}
+
+	idx := NewLineIndex(input, WithUnicodeLineTerminators())
+	require.Equal(t, Position{Line: 3, Column: 4, Offset: 28}, idx.Position(28))
+	offset, err := idx.Offset(Position{Line: 2, Column: 5})
+	require.NoError(t, err)
+	require.Equal(t, int64(15), offset)
+	require.Equal(t, Position{Line: 1, Column: 25, Offset: 28}, OffsetToPosition(input, 28))
+}
+
+func TestGetPositionsMaxDepth(t *testing.T) {
+	ptrs := []jsonpointer.Pointer{mustPointer("/a/0/0")}
+
+	_, err := GetPositions(`{"a": [[1]]}`, ptrs, WithMaxDepth(3))
+	require.NoError(t, err)
+
+	var depthErr *MaxDepthError
+	_, err = GetPositions(`{"a": [[1]]}`, ptrs, WithMaxDepth(2))
+	require.ErrorAs(t, err, &depthErr)
+	require.Equal(t, &MaxDepthError{MaxDepth: 2, Offset: 7}, depthErr)
+
+	// The drained values are limited as well
+	_, err = GetPositions(`{"b": [[1]], "a": [[1]]}`, ptrs, WithMaxDepth(2))
+	require.ErrorAs(t, err, &depthErr)
+	require.Equal(t, &MaxDepthError{MaxDepth: 2, Offset: 7}, depthErr)
+
+	// A pathologically deep document results in an error, rather than a stack overflow
+	n := 1000000
+	input := strings.Repeat("[", n) + strings.Repeat("]", n)
+	_, err = GetPositions(input, []jsonpointer.Pointer{mustPointer("/0/0/1")})
+	require.Error(t, err)
+	_, err = GetAllPositions(input)
+	require.Error(t, err)
+
+	// The default limit applies to any TokenReader, e.g. the one of JSON5, which has no limit of its own
+	for _, opts := range [][]Option{{WithJSON5()}, {WithJSON5(), WithRecover()}} {
+		_, err = GetPositions(input, []jsonpointer.Pointer{mustPointer("/0/0/1")}, opts...)
+		require.ErrorAs(t, err, &depthErr)
+		require.Equal(t, &MaxDepthError{MaxDepth: 10000, Offset: 10000}, depthErr)
+	}
+	input = strings.Repeat("[", 10000) + strings.Repeat("]", 10000)
+	_, err = GetPositions(input, []jsonpointer.Pointer{mustPointer("/0/0/1")}, WithJSON5())
+	require.NoError(t, err)
+}
+
+func TestGetPositionsEscaped(t *testing.T) {
+	input := `{"\u0066\u006f\u006f": 1, "b\"\\": "\u00e9\"\\", "c": "x"}`
+	ptrs := []jsonpointer.Pointer{mustPointer("/foo"), mustPointer(`/b"\`), mustPointer("/c")}
+	expect := map[string]JSONPointerPosition{
+		"/foo": {
+			Ptr:            mustPointer("/foo"),
+			Depth:          1,
+			Kind:           KindNumber,
+			Position:       Position{Line: 1, Column: 24, Offset: 23},
+			EndPosition:    Position{Line: 1, Column: 24, Offset: 23},
+			ByteLength:     1,
+			KeyPosition:    &Position{Line: 1, Column: 2, Offset: 1},
+			KeyEndPosition: &Position{Line: 1, Column: 21, Offset: 20},
+			ColonPosition:  &Position{Line: 1, Column: 22, Offset: 21},
+			ParentPosition: &Position{Line: 1, Column: 1, Offset: 0},
+			CommaPosition:  &Position{Line: 1, Column: 25, Offset: 24},
+			Raw:            "1",
+		},
+		`/b"\`: {
+			Ptr:            mustPointer(`/b"\`),
+			Depth:          1,
+			Kind:           KindString,
+			Position:       Position{Line: 1, Column: 36, Offset: 35},
+			EndPosition:    Position{Line: 1, Column: 47, Offset: 46},
+			ByteLength:     12,
+			KeyPosition:    &Position{Line: 1, Column: 27, Offset: 26},
+			KeyEndPosition: &Position{Line: 1, Column: 33, Offset: 32},
+			ColonPosition:  &Position{Line: 1, Column: 34, Offset: 33},
+			ParentPosition: &Position{Line: 1, Column: 1, Offset: 0},
+			CommaPosition:  &Position{Line: 1, Column: 25, Offset: 24},
+			Raw:            `"\u00e9\"\\"`,
+		},
+		"/c": {
+			Ptr:            mustPointer("/c"),
+			Depth:          1,
+			Kind:           KindString,
+			Position:       Position{Line: 1, Column: 55, Offset: 54},
+			EndPosition:    Position{Line: 1, Column: 57, Offset: 56},
+			ByteLength:     3,
+			KeyPosition:    &Position{Line: 1, Column: 50, Offset: 49},
+			KeyEndPosition: &Position{Line: 1, Column: 52, Offset: 51},
+			ColonPosition:  &Position{Line: 1, Column: 53, Offset: 52},
+			ParentPosition: &Position{Line: 1, Column: 1, Offset: 0},
+			CommaPosition:  &Position{Line: 1, Column: 48, Offset: 47},
+			Raw:            `"x"`,
+		},
+	}
+
+	out, err := GetPositions(input, ptrs, WithRaw())
+	require.NoError(t, err)
+	require.Equal(t, expect, out)
+
+	out, err = GetPositionsReader(iotest.OneByteReader(strings.NewReader(input)), ptrs, WithRaw())
+	require.NoError(t, err)
+	require.Equal(t, expect, out)
+
+	out, err = GetPositionsBytes([]byte(input), ptrs, WithRaw())
+	require.NoError(t, err)
+	require.Equal(t, expect, out)
+}
+
+func TestGetPositionsReportMissing(t *testing.T) {
+	input := `{"a": {"b": 1}}`
+	ptrs := []jsonpointer.Pointer{mustPointer("/a/b"), mustPointer("/a/c"), mustPointer("/x/y"), mustPointer("/*/z")}
+
+	out, err := GetPositions(input, ptrs, WithWildcards())
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+
+	out, err = GetPositions(input, ptrs, WithReportMissing(), WithWildcards())
+	require.NoError(t, err)
+	require.Len(t, out, 3)
+	require.False(t, out["/a/b"].Missing)
+	require.Equal(t, JSONPointerPosition{Ptr: mustPointer("/a/c"), Depth: 2, Missing: true, MissingReason: MissingKey}, out["/a/c"])
+	require.Equal(t, JSONPointerPosition{Ptr: mustPointer("/x/y"), Depth: 2, Missing: true, MissingReason: MissingKey}, out["/x/y"])
+
+	_, ok, err := GetPosition(input, mustPointer("/a/c"), WithReportMissing())
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestGetPositionsReader(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&sb, "  \"k%d\": [%d, \"v%d\", {\"x\": null}],\n", i, i, i)
+	}
+	sb.WriteString("  \"last\": true\n}")
+	input := sb.String()
+
+	var ptrs []jsonpointer.Pointer
+	for _, v := range []string{"/k0", "/k10/1", "/k500/2/x", "/k999/0", "/last"} {
+		ptr, err := jsonpointer.New(v)
+		require.NoError(t, err)
+		ptrs = append(ptrs, ptr)
+	}
+	expect, err := GetPositions(input, ptrs)
+	require.NoError(t, err)
+	require.Len(t, expect, 5)
+
+	out, err := GetPositionsReader(iotest.OneByteReader(strings.NewReader(input)), ptrs)
+	require.NoError(t, err)
+	require.Equal(t, expect, out)
+	require.Equal(t, Position{Line: 1002, Column: 11, Offset: int64(len(input) - 6)}, out["/last"].Position)
+}
+
+func TestGetPositionsBytes(t *testing.T) {
+	input := []byte(`{"a": [1, {"b": "😀"}], "c": "d"}`)
+	var ptrs []jsonpointer.Pointer
+	for _, v := range []string{"/a/1/b", "/c"} {
+		ptr, err := jsonpointer.New(v)
+		require.NoError(t, err)
+		ptrs = append(ptrs, ptr)
+	}
+	expect, err := GetPositions(string(input), ptrs)
+	require.NoError(t, err)
+	require.Len(t, expect, 2)
+
+	out, err := GetPositionsBytes(input, ptrs)
+	require.NoError(t, err)
+	require.Equal(t, expect, out)
+	require.Equal(t, `{"a": [1, {"b": "😀"}], "c": "d"}`, string(input))
+}
+
+func TestGetPositionsLeadingWhitespace(t *testing.T) {
+	input := "   \t\r\n  {   \"a\":   \t \"x\",\n\n    \"b\":\t\t[    1  ]  ,  \"c\" /* c */ :  // d\n  {}}  "
+	ptrs := []jsonpointer.Pointer{mustPointer(""), mustPointer("/a"), mustPointer("/b"), mustPointer("/b/0"), mustPointer("/c")}
+	for _, opts := range [][]Option{{WithComments()}, {WithJSON5()}} {
+		for _, r := range []func() io.Reader{
+			func() io.Reader { return strings.NewReader(input) },
+			func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+		} {
+			out, err := GetPositionsReader(r(), ptrs, opts...)
+			require.NoError(t, err)
+			// Each value starts at the first byte of its token
+			for k, c := range map[string]string{"": "{", "/a": `"`, "/b": "[", "/b/0": "1", "/c": "{"} {
+				offset := out[k].Offset
+				require.Equal(t, c, input[offset:offset+1], k)
+			}
+			require.Equal(t, Position{Line: 2, Column: 3, Offset: 8}, out[""].Position)
+			require.Equal(t, Position{Line: 2, Column: 16, Offset: 21}, out["/a"].Position)
+			require.Equal(t, Position{Line: 4, Column: 11, Offset: 37}, out["/b"].Position)
+			require.Equal(t, Position{Line: 4, Column: 16, Offset: 42}, out["/b/0"].Position)
+			require.Equal(t, Position{Line: 5, Column: 3, Offset: 73}, out["/c"].Position)
+		}
+	}
+}
+
+func TestGetPositionsRawMessage(t *testing.T) {
+	var doc struct {
+		Spec json.RawMessage `json:"spec"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"spec": {"a": [1, 2]}}`), &doc))
+	out, err := GetPositionsRaw(doc.Spec, []jsonpointer.Pointer{mustPointer("/a/1")})
+	require.NoError(t, err)
+	require.Equal(t, Position{Line: 1, Column: 11, Offset: 10}, out["/a/1"].Position)
+
+	out, err = GetPositionsRaw(doc.Spec, []jsonpointer.Pointer{mustPointer("/a/1")}, WithBaseOffset(9, 1, 10))
+	require.NoError(t, err)
+	require.Equal(t, Position{Line: 1, Column: 20, Offset: 19}, out["/a/1"].Position)
+}
+
+func TestMasker(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		opts   []Option
+		expect string
+	}{
+		{
+			name:   "comments",
+			input:  "{\"a/\\\"//\": 1, // x\n/* y **/ \"b\": [/**/2]}/",
+			opts:   []Option{WithComments()},
+			expect: "{\"a/\\\"//\": 1,     \n         \"b\": [    2]}/",
+		},
+		{
+			name:   "trailing commas",
+			input:  "{\"a,]\": [1, 2 ,\n], \"b\": {},}",
+			opts:   []Option{WithTrailingCommas()},
+			expect: "{\"a,]\": [1, 2  \n], \"b\": {} }",
+		},
+		{
+			name:   "trailing commas followed by comments",
+			input:  "[1, // x\n/*,*/]",
+			opts:   []Option{WithComments(), WithTrailingCommas()},
+			expect: "[1      \n     ]",
+		},
+		{
+			name:   "commas without values",
+			input:  "[[,], {,}, [1,,], {\"a\":,}, /**/,]",
+			opts:   []Option{WithComments(), WithTrailingCommas()},
+			expect: "[[,], {,}, [1,,], {\"a\":,},     ,]",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, r := range []io.Reader{strings.NewReader(tt.input), iotest.OneByteReader(strings.NewReader(tt.input))} {
+				b, err := io.ReadAll(newMasker(r, newOptions(tt.opts), nil))
+				require.NoError(t, err)
+				require.Equal(t, tt.expect, string(b))
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestGetPositionsParseError(t *testing.T) {
+	ptrs := []jsonpointer.Pointer{mustPointer("/c")}
+	cases := []struct {
+		name   string
+		input  string
+		opts   []Option
+		expect Position
+	}{
+		{
+			name:   "missing comma",
+			input:  "{\n  \"a\": 1\n  \"b\": 2\n}",
+			expect: Position{Line: 3, Column: 3, Offset: 13},
+		},
+		{
+			name:   "comma in empty array",
+			input:  "[,]",
+			opts:   []Option{WithTrailingCommas()},
+			expect: Position{Line: 1, Column: 2, Offset: 1},
+		},
+		{
+			name:   "comma in empty object",
+			input:  "{,}",
+			opts:   []Option{WithTrailingCommas()},
+			expect: Position{Line: 1, Column: 2, Offset: 1},
+		},
+		{
+			name:   "double trailing commas",
+			input:  "[1,,]",
+			opts:   []Option{WithTrailingCommas()},
+			expect: Position{Line: 1, Column: 4, Offset: 3},
+		},
+		{
+			name:   "missing comma zero based",
+			input:  "{\n  \"a\": 1\n  \"b\": 2\n}",
+			opts:   []Option{WithZeroBased()},
+			expect: Position{Line: 2, Column: 2, Offset: 13},
+		},
+		{
+			name:   "unexpected EOF",
+			input:  "{\n  \"a\": [1",
+			expect: Position{Line: 2, Column: 10, Offset: 11},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var perr *ParseError
+			_, err := GetPositions(tt.input, ptrs, tt.opts...)
+			require.ErrorAs(t, err, &perr)
+			require.Equal(t, tt.expect, perr.Position)
+			require.NotNil(t, perr.Unwrap())
+
+			_, err = GetPositionsReader(strings.NewReader(tt.input), ptrs, tt.opts...)
+			require.ErrorAs(t, err, &perr)
+			require.Equal(t, tt.expect, perr.Position)
+		})
+	}
+}
+
+func TestGetPositionsAppendToken(t *testing.T) {
+	input := `{"list": [1, 2 ], "empty": [ ], "obj": {"-": 1}}`
+	ptrs := []jsonpointer.Pointer{mustPointer("/list/-"), mustPointer("/empty/-"), mustPointer("/obj/-"), mustPointer("/list/-/a")}
+	expect := map[string]JSONPointerPosition{
+		"/list/-": {
+			Ptr:            mustPointer("/list/-"),
+			Depth:          2,
+			Position:       Position{Line: 1, Column: 15, Offset: 14},
+			EndPosition:    Position{Line: 1, Column: 15, Offset: 14},
+			ParentPosition: &Position{Line: 1, Column: 10, Offset: 9},
+		},
+		"/empty/-": {
+			Ptr:            mustPointer("/empty/-"),
+			Depth:          2,
+			Position:       Position{Line: 1, Column: 29, Offset: 28},
+			EndPosition:    Position{Line: 1, Column: 29, Offset: 28},
+			ParentPosition: &Position{Line: 1, Column: 28, Offset: 27},
+		},
+		// The "-" is an ordinary key within an object
+		"/obj/-": {
+			Ptr:            mustPointer("/obj/-"),
+			Depth:          2,
+			Kind:           KindNumber,
+			Position:       Position{Line: 1, Column: 46, Offset: 45},
+			EndPosition:    Position{Line: 1, Column: 46, Offset: 45},
+			ByteLength:     1,
+			KeyPosition:    &Position{Line: 1, Column: 41, Offset: 40},
+			KeyEndPosition: &Position{Line: 1, Column: 43, Offset: 42},
+			ColonPosition:  &Position{Line: 1, Column: 44, Offset: 43},
+			ParentPosition: &Position{Line: 1, Column: 40, Offset: 39},
+		},
+	}
+	actual, err := GetPositions(input, ptrs)
+	require.NoError(t, err)
+	require.Equal(t, expect, actual)
+
+	actual, err = GetPositions(input, ptrs, WithRuneLength(), WithRaw())
+	require.NoError(t, err)
+	require.Equal(t, expect["/list/-"], actual["/list/-"])
+}
+
+func TestGetPositionsMissingReason(t *testing.T) {
+	input := `{"arr": [1, 2, 3], "obj": {"a": 1}, "str": "x"}`
+	cases := []struct {
+		ptr    string
+		expect MissingReason
+	}{
+		{ptr: "/arr/99", expect: MissingIndexOutOfRange},
+		{ptr: "/arr/3", expect: MissingIndexOutOfRange},
+		{ptr: "/arr/01", expect: MissingInvalidIndex},
+		{ptr: "/arr/x", expect: MissingInvalidIndex},
+		{ptr: "/obj/99", expect: MissingKey},
+		{ptr: "/obj/b/c", expect: MissingKey},
+		{ptr: "/str/0", expect: MissingNotContainer},
+		{ptr: "/arr/0/x", expect: MissingNotContainer},
+	}
+	for _, tt := range cases {
+		t.Run(tt.ptr, func(t *testing.T) {
+			ptrs := []jsonpointer.Pointer{mustPointer(tt.ptr), mustPointer("/arr/2")}
+			out, err := GetPositions(input, ptrs, WithReportMissing())
+			require.NoError(t, err)
+			require.True(t, out[tt.ptr].Missing)
+			require.Equal(t, tt.expect, out[tt.ptr].MissingReason)
+			// The elements before the close bracket are still found
+			require.Equal(t, Position{Line: 1, Column: 16, Offset: 15}, out["/arr/2"].Position)
+		})
+	}
+}
+
+func TestGetPositionsErrorOnMissing(t *testing.T) {
+	input := `{"a": {"b": 1}, "c": [2]}`
+	ptrs := []jsonpointer.Pointer{mustPointer("/x"), mustPointer("/a/b"), mustPointer("/c/5"), mustPointer("/x"), mustPointer("/z/*")}
+
+	_, err := GetPositions(input, ptrs, WithErrorOnMissing(), WithReportMissing(), WithWildcards())
+	var nerr *ErrPointerNotFound
+	require.ErrorAs(t, err, &nerr)
+	require.Equal(t, []jsonpointer.Pointer{mustPointer("/x"), mustPointer("/c/5")}, nerr.Missing)
+	require.EqualError(t, err, `pointers not found: "/x", "/c/5"`)
+
+	out, err := GetPositions(input, ptrs[1:2], WithErrorOnMissing())
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+
+	_, err = GetPositionsYAML("a:\n  b: 1\n", []jsonpointer.Pointer{mustPointer("/a/b"), mustPointer("/a/c")}, WithErrorOnMissing())
+	require.ErrorAs(t, err, &nerr)
+	require.Equal(t, []jsonpointer.Pointer{mustPointer("/a/c")}, nerr.Missing)
+}
+
+func TestGetPositionsPartial(t *testing.T) {
+	input := `{"a": {"b": 1, "c": [true, "x"], "d": "tru`
+	ptrs := []jsonpointer.Pointer{mustPointer("/a/b"), mustPointer("/a/c"), mustPointer("/a/c/1"), mustPointer("/a/d"), mustPointer("/e")}
+	for _, get := range []func(opts ...Option) (map[string]JSONPointerPosition, error){
+		func(opts ...Option) (map[string]JSONPointerPosition, error) {
+			return GetPositions(input, ptrs, opts...)
+		},
+		func(opts ...Option) (map[string]JSONPointerPosition, error) {
+			return GetPositionsReader(iotest.OneByteReader(strings.NewReader(input)), ptrs, opts...)
+		},
+		func(opts ...Option) (map[string]JSONPointerPosition, error) {
+			return GetPositionsBytes([]byte(input), ptrs, opts...)
+		},
+		func(opts ...Option) (map[string]JSONPointerPosition, error) {
+			return GetPositions(input, ptrs, append(opts, WithJSON5())...)
+		},
+	} {
+		_, err := get()
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrTruncated)
+
+		out, err := get(WithPartial(), WithReportMissing(), WithAncestors(), WithErrorOnMissing())
+		require.ErrorIs(t, err, ErrTruncated)
+		var perr *ParseError
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, Position{Line: 1, Column: 43, Offset: 42}, perr.Position)
+		require.Equal(t, []string{"/a/b", "/a/c", "/a/c/1", "/a/d", "/e"}, sortedKeys(out))
+		require.Equal(t, Position{Line: 1, Column: 13, Offset: 12}, out["/a/b"].Position)
+		require.Equal(t, Position{Line: 1, Column: 21, Offset: 20}, out["/a/c"].Position)
+		require.Equal(t, Position{Line: 1, Column: 31, Offset: 30}, out["/a/c"].EndPosition)
+		require.Equal(t, Position{Line: 1, Column: 28, Offset: 27}, out["/a/c/1"].Position)
+		// The incomplete values are missing without any ancestor
+		for _, k := range []string{"/a/d", "/e"} {
+			require.True(t, out[k].Missing, k)
+			require.Equal(t, MissingUnknown, out[k].MissingReason, k)
+			require.Nil(t, out[k].Ancestor, k)
+		}
+	}
+
+	all, err := GetAllPositions(`[1, {"a": [2, 3], "b"`, WithPartial())
+	require.ErrorIs(t, err, ErrTruncated)
+	require.Equal(t, []string{"/0", "/1/a", "/1/a/0", "/1/a/1"}, sortedKeys(all))
+
+	// The syntax errors before the end are not regarded as truncation
+	for _, input := range []string{`{"a": 1 "b"`, `[1, 2]]`, `[1, x`} {
+		_, err := GetPositions(input, []jsonpointer.Pointer{mustPointer("/x")}, WithPartial())
+		require.Error(t, err, input)
+		require.NotErrorIs(t, err, ErrTruncated, input)
+	}
+}
+
+func TestGetPositionsTraversesScalar(t *testing.T) {
+	input := `{"a": 5, "b": {"c": "x", "d": [true, null]}, "e": {}}`
+	cases := []struct {
+		ptr    string
+		scalar string
+		kind   Kind
+	}{
+		{ptr: "/a/b", scalar: "/a", kind: KindNumber},
+		{ptr: "/a/b/c", scalar: "/a", kind: KindNumber},
+		{ptr: "/b/c/0", scalar: "/b/c", kind: KindString},
+		{ptr: "/b/d/0/x", scalar: "/b/d/0", kind: KindBoolean},
+		{ptr: "/b/d/1/x", scalar: "/b/d/1", kind: KindNull},
+	}
+	for _, tt := range cases {
+		t.Run(tt.ptr, func(t *testing.T) {
+			out, err := GetPositions(input, []jsonpointer.Pointer{mustPointer(tt.ptr)}, WithReportMissing())
+			require.NoError(t, err)
+			require.Equal(t, MissingNotContainer, out[tt.ptr].MissingReason)
+			var serr *ErrPointerTraversesScalar
+			require.ErrorAs(t, out[tt.ptr].Err, &serr)
+			require.Equal(t, tt.ptr, serr.Ptr.String())
+			require.Equal(t, tt.scalar, serr.Scalar.String())
+			require.Equal(t, tt.kind, serr.Kind)
+		})
+	}
+
+	// The genuinely absent pointers have no error
+	out, err := GetPositions(input, []jsonpointer.Pointer{mustPointer("/e/x"), mustPointer("/x/y")}, WithReportMissing())
+	require.NoError(t, err)
+	require.Equal(t, MissingKey, out["/e/x"].MissingReason)
+	require.NoError(t, out["/e/x"].Err)
+	require.NoError(t, out["/x/y"].Err)
+
+	require.EqualError(t, &ErrPointerTraversesScalar{Ptr: mustPointer("/a/b"), Scalar: mustPointer("/a"), Kind: KindNumber},
+		`pointer "/a/b" traverses the number at "/a"`)
+}
+
+func TestGetPositionsExpandObjects(t *testing.T) {
+	input := `{"config": {"a": 1, "b": {"c": 2}}, "list": [{"x": 1}], "s": "x"}`
+	all, err := GetAllPositions(input)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		ptrs   []string
+		opts   []Option
+		expect []string
+	}{
+		{
+			name:   "not expanded by default",
+			ptrs:   []string{"/config"},
+			expect: []string{"/config"},
+		},
+		{
+			name:   "object",
+			ptrs:   []string{"/config"},
+			opts:   []Option{WithExpandObjects()},
+			expect: []string{"/config", "/config/a", "/config/b"},
+		},
+		{
+			name:   "object with a requested member",
+			ptrs:   []string{"/config", "/config/b/c"},
+			opts:   []Option{WithExpandObjects()},
+			expect: []string{"/config", "/config/a", "/config/b", "/config/b/c"},
+		},
+		{
+			name:   "array and scalar",
+			ptrs:   []string{"/list", "/s"},
+			opts:   []Option{WithExpandObjects()},
+			expect: []string{"/list", "/s"},
+		},
+		{
+			name:   "wildcard",
+			ptrs:   []string{"/list/*"},
+			opts:   []Option{WithExpandObjects(), WithWildcards()},
+			expect: []string{"/list/0", "/list/0/x"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var ptrs []jsonpointer.Pointer
+			for _, v := range tt.ptrs {
+				ptrs = append(ptrs, mustPointer(v))
+			}
+			expect := map[string]JSONPointerPosition{}
+			for _, k := range tt.expect {
+				expect[k] = all[k]
+			}
+			out, err := GetPositions(input, ptrs, tt.opts...)
+			require.NoError(t, err)
+			require.Equal(t, expect, out)
+		})
+	}
+}
+
+func TestGetPositionsExpandArrays(t *testing.T) {
+	input := `{"items": [1, {"a": 2}, [3]], "obj": {"b": 4}}`
+	all, err := GetAllPositions(input)
+	require.NoError(t, err)
+
+	cases := []struct {
+		name   string
+		ptrs   []string
+		opts   []Option
+		expect []string
+	}{
+		{
+			name:   "array",
+			ptrs:   []string{"/items"},
+			opts:   []Option{WithExpandArrays()},
+			expect: []string{"/items", "/items/0", "/items/1", "/items/2"},
+		},
+		{
+			name:   "object not expanded",
+			ptrs:   []string{"/obj", "/items/1"},
+			opts:   []Option{WithExpandArrays()},
+			expect: []string{"/obj", "/items/1"},
+		},
+		{
+			name:   "append token",
+			ptrs:   []string{"/items", "/items/-"},
+			opts:   []Option{WithExpandArrays()},
+			expect: []string{"/items", "/items/0", "/items/1", "/items/2", "/items/-"},
+		},
+		{
+			name:   "with objects",
+			ptrs:   []string{"/items/1", "/items/2", "/obj"},
+			opts:   []Option{WithExpandArrays(), WithExpandObjects()},
+			expect: []string{"/items/1", "/items/1/a", "/items/2", "/items/2/0", "/obj", "/obj/b"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var ptrs []jsonpointer.Pointer
+			for _, v := range tt.ptrs {
+				ptrs = append(ptrs, mustPointer(v))
+			}
+			out, err := GetPositions(input, ptrs, tt.opts...)
+			require.NoError(t, err)
+			require.Equal(t, len(tt.expect), len(out))
+			for _, k := range tt.expect {
+				if k == "/items/-" {
+					require.Contains(t, out, k)
+					continue
+				}
+				require.Equal(t, all[k], out[k], k)
+			}
+		})
+	}
+}
+
+func TestGetPositionsNumbers(t *testing.T) {
+	input := `{"n": [1.23e+10, -0.5E-3, -12, 0.25, 1E5]}`
+	cases := []struct {
+		ptr   string
+		start int64
+		end   int64
+		raw   string
+	}{
+		{ptr: "/n/0", start: 7, end: 14, raw: "1.23e+10"},
+		{ptr: "/n/1", start: 17, end: 23, raw: "-0.5E-3"},
+		{ptr: "/n/2", start: 26, end: 28, raw: "-12"},
+		{ptr: "/n/3", start: 31, end: 34, raw: "0.25"},
+		{ptr: "/n/4", start: 37, end: 39, raw: "1E5"},
+	}
+	var ptrs []jsonpointer.Pointer
+	for _, tt := range cases {
+		ptrs = append(ptrs, mustPointer(tt.ptr))
+	}
+	out, err := GetPositions(input, ptrs, WithRaw())
+	require.NoError(t, err)
+	for _, tt := range cases {
+		pos := out[tt.ptr]
+		require.Equal(t, Position{Line: 1, Column: int(tt.start) + 1, Offset: tt.start}, pos.Position, tt.ptr)
+		require.Equal(t, Position{Line: 1, Column: int(tt.end) + 1, Offset: tt.end}, pos.EndPosition, tt.ptr)
+		require.Equal(t, len(tt.raw), pos.ByteLength, tt.ptr)
+		require.Equal(t, tt.raw, pos.Raw, tt.ptr)
+	}
+}
+
+func TestGetPositionsBigNumbers(t *testing.T) {
+	big := "1234567890123456789012345678901234567890"
+	decimal := "-3.14159265358979323846264338327950288419716939937510e-100"
+	input := "{\"big\": " + big + ", \"decimal\": [" + decimal + "], \"next\": 0}"
+	ptrs := []jsonpointer.Pointer{mustPointer("/big"), mustPointer("/decimal/0"), mustPointer("/next")}
+	for _, r := range []func() io.Reader{
+		func() io.Reader { return strings.NewReader(input) },
+		func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+	} {
+		out, err := GetPositionsReader(r(), ptrs, WithRaw())
+		require.NoError(t, err)
+
+		start := int64(strings.Index(input, big))
+		end := start + int64(len(big)) - 1
+		require.Equal(t, Position{Line: 1, Column: int(start) + 1, Offset: start}, out["/big"].Position)
+		require.Equal(t, Position{Line: 1, Column: int(end) + 1, Offset: end}, out["/big"].EndPosition)
+		require.Equal(t, len(big), out["/big"].ByteLength)
+		require.Equal(t, big, out["/big"].Raw)
+
+		start = int64(strings.Index(input, decimal))
+		end = start + int64(len(decimal)) - 1
+		require.Equal(t, Position{Line: 1, Column: int(start) + 1, Offset: start}, out["/decimal/0"].Position)
+		require.Equal(t, Position{Line: 1, Column: int(end) + 1, Offset: end}, out["/decimal/0"].EndPosition)
+		require.Equal(t, len(decimal), out["/decimal/0"].ByteLength)
+		require.Equal(t, decimal, out["/decimal/0"].Raw)
+
+		// The values after the numbers are not shifted
+		start = int64(len(input)) - 2
+		require.Equal(t, Position{Line: 1, Column: int(start) + 1, Offset: start}, out["/next"].Position)
+	}
+}
+
+func TestGetPositionsTrailing(t *testing.T) {
+	// The missing pointer makes the whole value walked
+	ptrs := []jsonpointer.Pointer{mustPointer("/a"), mustPointer("/missing")}
+	cases := []struct {
+		name  string
+		input string
+		opts  []Option
+		a     Position
+		err   *Position
+		errIs error
+	}{
+		{
+			name:  "leading whitespaces",
+			input: "\n\t  {\"a\": 1}",
+			a:     Position{Line: 2, Column: 10, Offset: 10},
+		},
+		{
+			name:  "trailing whitespaces",
+			input: "{\"a\": 1}\n \r\n",
+			a:     Position{Line: 1, Column: 7, Offset: 6},
+		},
+		{
+			name:  "trailing comments",
+			input: "{\"a\": 1} // x\n/* y */",
+			opts:  []Option{WithComments()},
+			a:     Position{Line: 1, Column: 7, Offset: 6},
+		},
+		{
+			name:  "trailing garbage",
+			input: "{\"a\": 1}\n x",
+			err:   &Position{Line: 2, Column: 2, Offset: 10},
+		},
+		{
+			name:  "trailing value",
+			input: "{\"a\": 1}\n {}",
+			err:   &Position{Line: 2, Column: 2, Offset: 10},
+			errIs: ErrTrailingContent,
+		},
+		{
+			name:  "trailing value after comments",
+			input: "{\"a\": 1} /* x */ 2",
+			opts:  []Option{WithComments()},
+			err:   &Position{Line: 1, Column: 18, Offset: 17},
+			errIs: ErrTrailingContent,
+		},
+		{
+			name:  "trailing garbage allowed",
+			input: "{\"a\": 1} x",
+			opts:  []Option{WithAllowTrailing()},
+			a:     Position{Line: 1, Column: 7, Offset: 6},
+		},
+		{
+			name:  "trailing value allowed",
+			input: "{\"a\": 1} {}",
+			opts:  []Option{WithAllowTrailing()},
+			a:     Position{Line: 1, Column: 7, Offset: 6},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := GetPositions(tt.input, ptrs, tt.opts...)
+			if tt.err != nil {
+				var perr *ParseError
+				require.ErrorAs(t, err, &perr)
+				require.Equal(t, *tt.err, perr.Position)
+				if tt.errIs != nil {
+					require.ErrorIs(t, err, tt.errIs)
+				}
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.a, out["/a"].Position)
+		})
+	}
+
+	// Whether the trailing content is accepted doesn't depend on the pointers, though all of them are found early for /0
+	for _, ptrs := range [][]jsonpointer.Pointer{
+		{mustPointer("/0")},
+		{mustPointer("/0"), mustPointer("/5")},
+	} {
+		var perr *ParseError
+		_, err := GetPositions(`[1] x`, ptrs)
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, Position{Line: 1, Column: 5, Offset: 4}, perr.Position)
+		_, err = GetPositionsReader(strings.NewReader(`[1] x`), ptrs)
+		require.ErrorAs(t, err, &perr)
+		require.Equal(t, Position{Line: 1, Column: 5, Offset: 4}, perr.Position)
+		_, err = GetPositions(`[1] 2`, ptrs)
+		require.ErrorIs(t, err, ErrTrailingContent)
+		_, err = GetPositions(`[1] x`, ptrs, WithAllowTrailing())
+		require.NoError(t, err)
+	}
+}
+
+func TestGetPositionsDepth(t *testing.T) {
+	input := `{"a": {"b": {"c": 1}}}`
+	out, err := GetPositions(input, []jsonpointer.Pointer{mustPointer(""), mustPointer("/a"), mustPointer("/a/b/c")})
+	require.NoError(t, err)
+	require.Equal(t, 0, out[""].Depth)
+	require.Equal(t, 1, out["/a"].Depth)
+	require.Equal(t, 3, out["/a/b/c"].Depth)
+
+	// The depth of a missing pointer is counted from the pointer as well
+	out, err = GetPositions(input, []jsonpointer.Pointer{mustPointer("/x"), mustPointer("/a/b/c/d")}, WithReportMissing())
+	require.NoError(t, err)
+	require.Equal(t, 1, out["/x"].Depth)
+	require.Equal(t, 4, out["/a/b/c/d"].Depth)
+}
+
+func TestGetPositionsComma(t *testing.T) {
+	input := "{\"a\": [1 , 2, 3], \"b\": {\"x\": 1}, \"c\": [4,], \"d\": [5\n  // c\n  , 6]}"
+	opts := []Option{WithComments(), WithTrailingCommas()}
+	// The offsets of the expected commas, where -1 means no comma
+	expect := map[string]int64{
+		"":     -1,
+		"/a":   16, // The first member is followed by the comma
+		"/a/0": 9,
+		"/a/1": 9,
+		"/a/2": 12,
+		"/b":   16,
+		"/b/x": -1, // The only member
+		"/c/0": 40, // The trailing comma
+		"/d":   42,
+		"/d/0": 61, // Skipping the comment
+		"/d/1": 61,
+	}
+	var ptrs []jsonpointer.Pointer
+	for k := range expect {
+		ptrs = append(ptrs, mustPointer(k))
+	}
+	for _, r := range []func() io.Reader{
+		func() io.Reader { return strings.NewReader(input) },
+		func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+	} {
+		out, err := GetPositionsReader(r(), ptrs, opts...)
+		require.NoError(t, err)
+		require.Len(t, out, len(expect))
+		for k, offset := range expect {
+			if offset < 0 {
+				require.Nil(t, out[k].CommaPosition, k)
+				continue
+			}
+			require.NotNil(t, out[k].CommaPosition, k)
+			require.Equal(t, offset, out[k].CommaPosition.Offset, k)
+		}
+		require.Equal(t, &Position{Line: 3, Column: 3, Offset: 61}, out["/d/1"].CommaPosition)
+	}
+
+	// The comma following the first element is found even if the walk stops early
+	pos, ok, err := GetPosition(input, mustPointer("/a/0"), opts...)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, &Position{Line: 1, Column: 10, Offset: 9}, pos.CommaPosition)
+}
+
+func TestGetPositionsClose(t *testing.T) {
+	input := "{\n  \"obj\": {\n    \"a\": [1, 2],\n    \"b\": \"x\"\n  }\n}"
+	out, err := GetPositions(input, []jsonpointer.Pointer{mustPointer("/obj"), mustPointer("/obj/a"), mustPointer("/obj/b")})
+	require.NoError(t, err)
+	require.Equal(t, &Position{Line: 5, Column: 3, Offset: 45}, out["/obj"].ClosePosition)
+	require.Equal(t, out["/obj"].EndPosition, *out["/obj"].ClosePosition)
+	require.Equal(t, &Position{Line: 3, Column: 15, Offset: 27}, out["/obj/a"].ClosePosition)
+	require.Nil(t, out["/obj/b"].ClosePosition)
+}
+
+// countingTokenReader is a TokenReader that counts the tokens read by the wrapped decoder.
+type countingTokenReader struct {
+	*json.Decoder
+	tokens int
+}
+
+func (r *countingTokenReader) Token() (json.Token, error) {
+	r.tokens++
+	return r.Decoder.Token()
+}
+
+func TestGetPositionsTokenReader(t *testing.T) {
+	input := "{\"a\": [1, \"x\"], // c\n \"b\": {\"c\": null,},}"
+	ptrs := []jsonpointer.Pointer{mustPointer("/a/1"), mustPointer("/b/c"), mustPointer("/d")}
+	expect, err := GetPositions(input, ptrs, WithComments(), WithTrailingCommas(), WithRaw())
+	require.NoError(t, err)
+
+	var tr *countingTokenReader
+	out, err := GetPositions(input, ptrs, WithComments(), WithTrailingCommas(), WithRaw(), WithTokenReader(func(r io.Reader) TokenReader {
+		dec := json.NewDecoder(r)
+		dec.UseNumber()
+		tr = &countingTokenReader{Decoder: dec}
+		return tr
+	}))
+	require.NoError(t, err)
+	require.Equal(t, expect, out)
+	require.NotZero(t, tr.tokens)
+}
+
+// rawNewlineReader replaces the raw line feeds within the strings with spaces, so that the documents with them are
+// accepted by *json.Decoder as the lenient parsers do, without changing the offsets.
+type rawNewlineReader struct {
+	r        io.Reader
+	inString bool
+	escaped  bool
+}
+
+func (r *rawNewlineReader) Read(b []byte) (int, error) {
+	n, err := r.r.Read(b)
+	for i := range b[:n] {
+		switch {
+		case r.escaped:
+			r.escaped = false
+		case r.inString && b[i] == '\\':
+			r.escaped = true
+		case b[i] == '"':
+			r.inString = !r.inString
+		case r.inString && b[i] == '\n':
+			b[i] = ' '
+		}
+	}
+	return n, err
+}
+
+func TestGetPositionsNewlinesInStrings(t *testing.T) {
+	// The escaped line breaks are not line breaks
+	input := `{"a": "x\ny\r\n\u000a", "c": 3}`
+	out, err := GetPositions(input, []jsonpointer.Pointer{mustPointer("/a"), mustPointer("/c")})
+	require.NoError(t, err)
+	require.Equal(t, Position{Line: 1, Column: 22, Offset: 21}, out["/a"].EndPosition)
+	require.Equal(t, Position{Line: 1, Column: 30, Offset: 29}, out["/c"].Position)
+
+	// The raw line feeds accepted by a lenient TokenReader are
+	input = "{\"a\": \"x\\ny\\r\\n\\u000a\", \"b\": \"1\n2\", \"c\": 3}"
+	lenient := WithTokenReader(func(r io.Reader) TokenReader {
+		dec := json.NewDecoder(&rawNewlineReader{r: r})
+		dec.UseNumber()
+		return dec
+	})
+	ptrs := []jsonpointer.Pointer{mustPointer("/b"), mustPointer("/c")}
+	_, err = GetPositions(input, ptrs)
+	require.Error(t, err)
+	for _, r := range []func() io.Reader{
+		func() io.Reader { return strings.NewReader(input) },
+		func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) },
+	} {
+		out, err := GetPositionsReader(r(), ptrs, lenient, WithRaw())
+		require.NoError(t, err)
+		require.Equal(t, Position{Line: 1, Column: 30, Offset: 29}, out["/b"].Position)
+		require.Equal(t, Position{Line: 2, Column: 2, Offset: 33}, out["/b"].EndPosition)
+		require.Equal(t, "\"1\n2\"", out["/b"].Raw)
+		require.Equal(t, Position{Line: 2, Column: 10, Offset: 41}, out["/c"].Position)
+	}
+}
+
+func TestGetPositionsCaseInsensitiveKeys(t *testing.T) {
+	input := `{"port": 1, "Host": {"NAME": "x"}, "PORT": 2, "Name": 3, "name": 4}`
+	ptrs := []jsonpointer.Pointer{mustPointer("/Port"), mustPointer("/host/name"), mustPointer("/name"), mustPointer("/NAME")}
+
+	out, err := GetPositions(input, ptrs)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/name"}, sortedKeys(out))
+	require.Equal(t, int64(65), out["/name"].Offset)
+
+	out, err = GetPositions(input, ptrs, WithCaseInsensitiveKeys())
+	require.NoError(t, err)
+	require.Equal(t, []string{"/NAME", "/Port", "/host/name", "/name"}, sortedKeys(out))
+	// The last matching key is found like a duplicate key
+	require.Equal(t, mustPointer("/Port"), out["/Port"].Ptr)
+	require.Equal(t, int64(43), out["/Port"].Offset)
+	require.Equal(t, int64(29), out["/host/name"].Offset)
+	// The key of the same case is matched, otherwise the least token
+	require.Equal(t, int64(65), out["/name"].Offset)
+	require.Equal(t, int64(54), out["/NAME"].Offset)
+
+	out, err = GetPositions(input, ptrs, WithCaseInsensitiveKeys(), WithDuplicateKeys())
+	require.NoError(t, err)
+	require.Equal(t, []Position{{Line: 1, Column: 10, Offset: 9}, {Line: 1, Column: 44, Offset: 43}}, out["/Port"].DuplicatePositions)
+
+	// The pointers of the walked values are the specified ones
+	var walked []string
+	err = WalkPositions(input, ptrs, func(pos JSONPointerPosition) error {
+		walked = append(walked, pos.Ptr.String())
+		return nil
+	}, WithCaseInsensitiveKeys())
+	require.NoError(t, err)
+	require.Equal(t, []string{"/Port", "/host/name", "/NAME", "/name"}, walked)
+}
+
+func sortedKeys(m map[string]JSONPointerPosition) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func TestGetPositionsBaseOffset(t *testing.T) {
+	// The document is embedded at the byte offset 1024, line 40 and column 3 of an enclosing file
+	input := "[\t\"a\",\n\t{\"b\": 1}]"
+	ptrs := []jsonpointer.Pointer{mustPointer(""), mustPointer("/0"), mustPointer("/1/b")}
+	cases := []struct {
+		name   string
+		opts   []Option
+		expect map[string]Position
+	}{
+		{
+			name: "one-based",
+			opts: []Option{WithBaseOffset(1024, 40, 3), WithTabWidth(4)},
+			expect: map[string]Position{
+				// The tab stops of the first line are the ones of the enclosing file
+				"":     {Line: 40, Column: 3, Offset: 1024},
+				"/0":   {Line: 40, Column: 5, Offset: 1026},
+				"/1/b": {Line: 41, Column: 11, Offset: 1038},
+			},
+		},
+		{
+			name: "zero-based",
+			opts: []Option{WithBaseOffset(1024, 39, 2), WithZeroBased(), WithTabWidth(4)},
+			expect: map[string]Position{
+				"":     {Line: 39, Column: 2, Offset: 1024},
+				"/0":   {Line: 39, Column: 4, Offset: 1026},
+				"/1/b": {Line: 40, Column: 10, Offset: 1038},
+			},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := GetPositions(input, ptrs, tt.opts...)
+			require.NoError(t, err)
+			for k, pos := range tt.expect {
+				require.Equal(t, pos, out[k].Position, k)
+				require.Equal(t, pos, OffsetToPosition(input, pos.Offset, tt.opts...), k)
+				offset, err := PositionToOffset(input, pos, tt.opts...)
+				require.NoError(t, err)
+				require.Equal(t, pos.Offset, offset, k)
+			}
+			require.Equal(t, out[""].Position, *out["/0"].ParentPosition)
+		})
+	}
+
+	_, err := GetPositions(`{"a": }`, ptrs, WithBaseOffset(100, 5, 3))
+	var perr *ParseError
+	require.ErrorAs(t, err, &perr)
+	require.Equal(t, Position{Line: 5, Column: 9, Offset: 106}, perr.Position)
+
+	annotated, err := Annotate(`{"a": 1}`, WithBaseOffset(100, 5, 3))
+	require.NoError(t, err)
+	require.Equal(t, `/* "" 5:3 */ {"a": /* "/a" 5:9 */ 1}`, annotated)
+}
+
+func TestGetPositionsInsertPositions(t *testing.T) {
+	input := "{\n  \"a\": {\"x\": 1},\n  \"b\": [],\n  \"c\": 5\n}"
+	cases := []struct {
+		ptr      string
+		ancestor string
+		insert   *Position
+	}{
+		{ptr: "/a/y", ancestor: "/a", insert: &Position{Line: 2, Column: 15, Offset: 16}},
+		{ptr: "/a/y/z/0", ancestor: "/a", insert: &Position{Line: 2, Column: 15, Offset: 16}},
+		{ptr: "/b/0", ancestor: "/b", insert: &Position{Line: 3, Column: 9, Offset: 27}},
+		{ptr: "/d", ancestor: "", insert: &Position{Line: 5, Column: 1, Offset: 39}},
+		{ptr: "/c/z", ancestor: "/c"},
+	}
+	var ptrs []jsonpointer.Pointer
+	for _, tt := range cases {
+		ptrs = append(ptrs, mustPointer(tt.ptr))
+	}
+	out, err := GetPositions(input, ptrs, WithInsertPositions())
+	require.NoError(t, err)
+	for _, tt := range cases {
+		pos := out[tt.ptr]
+		require.True(t, pos.Missing, tt.ptr)
+		require.NotNil(t, pos.Ancestor, tt.ptr)
+		require.Equal(t, tt.ancestor, pos.Ancestor.String(), tt.ptr)
+		require.Equal(t, tt.insert, pos.InsertPosition, tt.ptr)
+	}
+
+	// The insert positions are only reported with the option
+	out, err = GetPositions(input, ptrs, WithReportMissing())
+	require.NoError(t, err)
+	require.Nil(t, out["/a/y"].Ancestor)
+	require.Nil(t, out["/a/y"].InsertPosition)
+}
+
+func TestGetPositionsAncestors(t *testing.T) {
+	input := `{"a": {"x": [true]}, "b": 1}`
+	cases := []struct {
+		ptr      string
+		ancestor string
+		pos      Position
+	}{
+		{ptr: "/a/b/c", ancestor: "/a", pos: Position{Line: 1, Column: 7, Offset: 6}},
+		{ptr: "/a/x/1", ancestor: "/a/x", pos: Position{Line: 1, Column: 13, Offset: 12}},
+		{ptr: "/a/x/0/y", ancestor: "/a/x/0", pos: Position{Line: 1, Column: 14, Offset: 13}},
+		{ptr: "/c", ancestor: "", pos: Position{Line: 1, Column: 1, Offset: 0}},
+	}
+	var ptrs []jsonpointer.Pointer
+	for _, tt := range cases {
+		ptrs = append(ptrs, mustPointer(tt.ptr))
+	}
+	out, err := GetPositions(input, append(ptrs, mustPointer("/b")), WithAncestors())
+	require.NoError(t, err)
+	for _, tt := range cases {
+		pos := out[tt.ptr]
+		require.True(t, pos.Missing, tt.ptr)
+		require.Equal(t, tt.ancestor, pos.Ancestor.String(), tt.ptr)
+		require.Equal(t, tt.pos, *pos.AncestorPosition, tt.ptr)
+		require.Nil(t, pos.InsertPosition, tt.ptr)
+	}
+	require.Nil(t, out["/b"].Ancestor)
+	require.Nil(t, out["/b"].AncestorPosition)
+}
+
+func TestPositionString(t *testing.T) {
+	require.Equal(t, "12:345", Position{Line: 12, Column: 345, Offset: 1000}.String())
+	require.Equal(t, "0:0", Position{}.String())
+
+	out, err := GetPositions("{\n  \"a\": 1\n}", []jsonpointer.Pointer{mustPointer("/a"), mustPointer("/b")}, WithReportMissing(), WithZeroBased())
+	require.NoError(t, err)
+	require.Equal(t, "1:7", out["/a"].Position.String())
+	require.Equal(t, `"/a" 1:7`, fmt.Sprint(out["/a"]))
+	require.Equal(t, `"/b" missing`, out["/b"].String())
+
+	pos := Position{Line: 3, Column: 4}
+	require.Equal(t, 1.0, testing.AllocsPerRun(10, func() { _ = pos.String() }))
+}
+
+func TestPositionCompare(t *testing.T) {
+	cases := []struct {
+		a, b   Position
+		expect int
+	}{
+		{a: Position{Line: 1, Column: 5, Offset: 4}, b: Position{Line: 1, Column: 5, Offset: 4}, expect: 0},
+		{a: Position{Line: 1, Column: 9, Offset: 8}, b: Position{Line: 2, Column: 1, Offset: 3}, expect: -1},
+		{a: Position{Line: 2, Column: 3, Offset: 3}, b: Position{Line: 2, Column: 2, Offset: 9}, expect: 1},
+		{a: Position{Line: 2, Column: 2, Offset: 3}, b: Position{Line: 2, Column: 2, Offset: 4}, expect: -1},
+	}
+	for _, tt := range cases {
+		require.Equal(t, tt.expect, tt.a.Compare(tt.b), "%v %v", tt.a, tt.b)
+		require.Equal(t, -tt.expect, tt.b.Compare(tt.a), "%v %v", tt.b, tt.a)
+		require.Equal(t, tt.expect < 0, tt.a.Less(tt.b), "%v %v", tt.a, tt.b)
+	}
+	a, b := cases[1].a, cases[1].b
+	require.Zero(t, testing.AllocsPerRun(10, func() { _ = a.Less(b) }))
+}
+
+func TestByPosition(t *testing.T) {
+	input := "{\n  \"b\": [1, 2],\n  \"a\": true\n}"
+	m, err := GetPositions(input, []jsonpointer.Pointer{mustPointer("/a"), mustPointer("/b/1"), mustPointer("/b"), mustPointer("/z"), mustPointer("/y")}, WithReportMissing())
+	require.NoError(t, err)
+	var xs []JSONPointerPosition
+	for _, pos := range m {
+		xs = append(xs, pos)
+	}
+	expect := []string{"/y", "/z", "/b", "/b/1", "/a"}
+
+	sort.Sort(ByPosition(xs))
+	var ptrs []string
+	for _, pos := range xs {
+		ptrs = append(ptrs, pos.Ptr.String())
+	}
+	require.Equal(t, expect, ptrs)
+
+	sort.Slice(xs, func(i, j int) bool { return xs[i].Ptr.String() < xs[j].Ptr.String() })
+	sort.Slice(xs, func(i, j int) bool { return ComparePositions(xs[i], xs[j]) < 0 })
+	ptrs = ptrs[:0]
+	for _, pos := range xs {
+		ptrs = append(ptrs, pos.Ptr.String())
+	}
+	require.Equal(t, expect, ptrs)
+	require.Zero(t, ComparePositions(xs[0], xs[0]))
 }
@@ -0,0 +1,77 @@
+//go:build goexperiment.jsonv2
+
+package jsonpointerpos
+
+import (
+	"encoding/json"
+	"encoding/json/jsontext"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// NewJSONTextReader returns a TokenReader backed by the jsontext.Decoder of the experimental encoding/json/v2, to be
+// used with WithTokenReader. It accepts the duplicate object keys and the invalid UTF-8 as *json.Decoder does, so the
+// positions are the same as the default ones. It is only available with GOEXPERIMENT=jsonv2.
+func NewJSONTextReader(r io.Reader) TokenReader {
+	return &jsontextReader{
+		dec: jsontext.NewDecoder(r, jsontext.AllowDuplicateNames(true), jsontext.AllowInvalidUTF8(true)),
+	}
+}
+
+type jsontextReader struct {
+	dec *jsontext.Decoder
+}
+
+func (r *jsontextReader) Token() (json.Token, error) {
+	tok, err := r.dec.ReadToken()
+	if err != nil {
+		var serr *jsontext.SyntacticError
+		if errors.As(err, &serr) {
+			return nil, &jsontextError{err: err, offset: serr.ByteOffset}
+		}
+		return nil, err
+	}
+	switch kind := tok.Kind(); kind {
+	case '{', '}', '[', ']':
+		return json.Delim(kind), nil
+	case 'n':
+		return nil, nil
+	case 't', 'f':
+		return tok.Bool(), nil
+	case '"':
+		return tok.String(), nil
+	case '0':
+		// The raw text of the number is kept, as with UseNumber
+		return json.Number(tok.String()), nil
+	default:
+		return nil, fmt.Errorf("unexpected token kind %v", kind)
+	}
+}
+
+func (r *jsontextReader) More() bool {
+	kind := r.dec.PeekKind()
+	return kind != 0 && kind != '}' && kind != ']'
+}
+
+func (r *jsontextReader) InputOffset() int64 {
+	return r.dec.InputOffset()
+}
+
+// jsontextError is a syntax error of jsontext, whose offset is the offending byte.
+type jsontextError struct {
+	err    error
+	offset int64
+}
+
+func (e *jsontextError) Error() string {
+	return e.err.Error()
+}
+
+func (e *jsontextError) Unwrap() error {
+	return e.err
+}
+
+func (e *jsontextError) ErrorOffset() int64 {
+	return e.offset
+}
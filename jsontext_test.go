@@ -0,0 +1,67 @@
+//go:build goexperiment.jsonv2
+
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONTextReader(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		opts  []Option
+	}{
+		{
+			name:  "nested",
+			input: "{\n  \"a\": [1, -0.5E-3, \"x\", true, false, null],\n  \"b\": {\"c\": {}, \"d\": []}\n}",
+		},
+		{
+			name:  "escapes and multibyte characters",
+			input: `{"foo": "é\"\\", "😀": ["\t"]}`,
+			opts:  []Option{WithUTF16Columns()},
+		},
+		{
+			name:  "duplicate keys",
+			input: `{"a": 1, "a": [2]}`,
+			opts:  []Option{WithDuplicateKeys()},
+		},
+		{
+			name:  "comments and trailing commas",
+			input: "// c\n{\"a\": [1,], /* c */ \"b\": 2,}",
+			opts:  []Option{WithComments(), WithTrailingCommas()},
+		},
+		{
+			name:  "BOM",
+			input: "\uFEFF[1]",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			expect, err := GetAllPositions(tt.input, append(tt.opts, WithRaw())...)
+			require.NoError(t, err)
+			out, err := GetAllPositions(tt.input, append(tt.opts, WithRaw(), WithTokenReader(NewJSONTextReader))...)
+			require.NoError(t, err)
+			require.Equal(t, expect, out)
+		})
+	}
+}
+
+func TestJSONTextReaderParseError(t *testing.T) {
+	for _, input := range []string{
+		"{\n  \"a\": 1\n  \"b\": 2\n}",
+		"[1x]",
+		"{\"a\": [1",
+		"{} x",
+		"{} {}",
+	} {
+		var expect, actual *ParseError
+		_, err := GetAllPositions(input)
+		require.ErrorAs(t, err, &expect, input)
+		_, err = GetAllPositions(input, WithTokenReader(NewJSONTextReader))
+		require.ErrorAs(t, err, &actual, input)
+		require.Equal(t, expect.Position, actual.Position, input)
+	}
+}
@@ -0,0 +1,90 @@
+// Package lsp converts the positions of jsonpointerpos to the ones of the Language Server Protocol (LSP), whose lines
+// and characters are zero-based, and whose characters are counted in UTF-16 code units.
+//
+// The types are defined in the same shape as the LSP specification (including the JSON field names), so that they
+// can be converted to the types of any LSP implementation directly.
+package lsp
+
+import (
+	"github.com/go-openapi/jsonpointer"
+	"github.com/magodo/jsonpointerpos"
+)
+
+// Position is a position in a text document, as defined by the LSP.
+type Position struct {
+	// Line is the zero-based line.
+	Line uint32 `json:"line"`
+	// Character is the zero-based character offset on the line, counted in UTF-16 code units.
+	Character uint32 `json:"character"`
+}
+
+// Range is a range in a text document, as defined by the LSP. The end position is exclusive.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Options returns the options that make jsonpointerpos compute the positions as the LSP requires, i.e. zero-based
+// lines and columns in UTF-16 code units. The positions passed to NewPosition and NewRange must be computed with them.
+func Options() []jsonpointerpos.Option {
+	return []jsonpointerpos.Option{
+		jsonpointerpos.WithZeroBased(),
+		jsonpointerpos.WithUTF16Columns(),
+	}
+}
+
+// NewPosition converts the position computed with Options to the LSP position.
+func NewPosition(pos jsonpointerpos.Position) Position {
+	return Position{
+		Line:      uint32(pos.Line),
+		Character: uint32(pos.Column),
+	}
+}
+
+// NewRange converts the span of the value computed with Options to the LSP range.
+func NewRange(pos jsonpointerpos.JSONPointerPosition) Range {
+	end := NewPosition(pos.EndPosition)
+	// The end position points to the last character of the value, which is always a single UTF-16 code unit, e.g. a
+	// closing quote/delimiter, a digit, or the last letter of a literal. The insertion point of the "-" token results
+	// in an empty range instead.
+	if pos.ByteLength != 0 {
+		end.Character++
+	}
+	return Range{
+		Start: NewPosition(pos.Position),
+		End:   end,
+	}
+}
+
+// NewKeyRange converts the span of the key computed with Options to the LSP range, including both quotes, e.g. to
+// select or rename the key. The returned bool is false if the value is not an object member.
+func NewKeyRange(pos jsonpointerpos.JSONPointerPosition) (Range, bool) {
+	if pos.KeyPosition == nil || pos.KeyEndPosition == nil {
+		return Range{}, false
+	}
+	end := NewPosition(*pos.KeyEndPosition)
+	// The closing quote is a single UTF-16 code unit
+	end.Character++
+	return Range{
+		Start: NewPosition(*pos.KeyPosition),
+		End:   end,
+	}, true
+}
+
+// GetRanges returns the LSP ranges of the values that the specified JSON pointers point to within the document,
+// keyed by the pointers. It is like jsonpointerpos.GetPositions, which is called with Options in addition to the
+// specified options.
+func GetRanges(document string, ptrs []jsonpointer.Pointer, opts ...jsonpointerpos.Option) (map[string]Range, error) {
+	m, err := jsonpointerpos.GetPositions(document, ptrs, append(Options(), opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]Range{}
+	for k, pos := range m {
+		if pos.Missing {
+			continue
+		}
+		out[k] = NewRange(pos)
+	}
+	return out, nil
+}
@@ -0,0 +1,75 @@
+package lsp
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/magodo/jsonpointerpos"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRanges(t *testing.T) {
+	input := "{\n  \"😀\": \"é\", \"a\": [true]\n}"
+	var ptrs []jsonpointer.Pointer
+	for _, v := range []string{"", "/😀", "/a", "/a/0", "/a/-", "/b"} {
+		ptr, err := jsonpointer.New(v)
+		require.NoError(t, err)
+		ptrs = append(ptrs, ptr)
+	}
+
+	out, err := GetRanges(input, ptrs, jsonpointerpos.WithReportMissing())
+	require.NoError(t, err)
+	require.Equal(t, map[string]Range{
+		"": {
+			Start: Position{Line: 0, Character: 0},
+			End:   Position{Line: 2, Character: 1},
+		},
+		"/😀": {
+			Start: Position{Line: 1, Character: 8},
+			End:   Position{Line: 1, Character: 11},
+		},
+		"/a": {
+			Start: Position{Line: 1, Character: 18},
+			End:   Position{Line: 1, Character: 24},
+		},
+		"/a/0": {
+			Start: Position{Line: 1, Character: 19},
+			End:   Position{Line: 1, Character: 23},
+		},
+		"/a/-": {
+			Start: Position{Line: 1, Character: 23},
+			End:   Position{Line: 1, Character: 23},
+		},
+	}, out)
+}
+
+func TestNewKeyRange(t *testing.T) {
+	input := "{\n  \"😀\\u0041\": [1]\n}"
+	var ptrs []jsonpointer.Pointer
+	for _, v := range []string{"", "/😀A", "/😀A/0"} {
+		ptr, err := jsonpointer.New(v)
+		require.NoError(t, err)
+		ptrs = append(ptrs, ptr)
+	}
+	out, err := jsonpointerpos.GetPositions(input, ptrs, Options()...)
+	require.NoError(t, err)
+
+	rng, ok := NewKeyRange(out["/😀A"])
+	require.True(t, ok)
+	require.Equal(t, Range{
+		Start: Position{Line: 1, Character: 2},
+		End:   Position{Line: 1, Character: 12},
+	}, rng)
+
+	_, ok = NewKeyRange(out[""])
+	require.False(t, ok)
+	_, ok = NewKeyRange(out["/😀A/0"])
+	require.False(t, ok)
+}
+
+func TestRangeJSON(t *testing.T) {
+	b, err := json.Marshal(Range{Start: Position{Line: 1, Character: 2}, End: Position{Line: 3, Character: 4}})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"start": {"line": 1, "character": 2}, "end": {"line": 3, "character": 4}}`, string(b))
+}
@@ -0,0 +1,243 @@
+package jsonpointerpos
+
+import (
+	"bytes"
+	"io"
+)
+
+type maskState int
+
+const (
+	maskNormal maskState = iota
+	maskString
+	maskStringEscape
+	maskLineComment
+	maskBlockComment
+	maskBlockCommentStar
+)
+
+// masker reads a JSON-like document from r, and replaces the non-standard parts (e.g. comments) with spaces, so
+// that it can be decoded as JSON. As these parts are masked instead of being removed, the offsets of the masked
+// document are the same as the original one.
+type masker struct {
+	r              io.Reader
+	comments       bool
+	trailingCommas bool
+	// singleQuotes recognizes the single-quoted strings of JSON5 as well.
+	singleQuotes bool
+
+	state maskState
+	// quote is the quote of the current string.
+	quote byte
+	chunk []byte
+	// out is the masked bytes that are ready to be read.
+	out []byte
+	// hold is the bytes that can't be released yet, as they depend on the following bytes.
+	// E.g. a slash that might start a comment, or a comma that might be a trailing one.
+	hold []byte
+	// resume is the index of hold, from where the masking continues.
+	resume int
+	// comma is the index of hold of the pending comma, or -1 if there is none.
+	comma int
+	// last is the last significant byte before the current one, where the closing quote of a string is significant.
+	last byte
+	// cr reports whether the last masked byte is a CR, so that a CRLF is recorded as a single line break.
+	cr  bool
+	err error
+
+	// comment is the offset of the comment being masked.
+	comment int64
+	// list, if not nil, records the masked comments.
+	list *commentList
+	// offset is the offset of the first byte of hold.
+	offset int64
+}
+
+func newMasker(r io.Reader, opts options, list *commentList) *masker {
+	return &masker{
+		r:              r,
+		comments:       opts.comments,
+		trailingCommas: opts.trailingCommas,
+		singleQuotes:   opts.json5,
+		chunk:          make([]byte, 4096),
+		comma:          -1,
+		list:           list,
+	}
+}
+
+func (m *masker) Read(b []byte) (int, error) {
+	for len(m.out) == 0 {
+		if m.err != nil {
+			return 0, m.err
+		}
+		n, err := m.r.Read(m.chunk)
+		m.err = err
+		m.out = m.mask(append(m.hold, m.chunk[:n]...))
+	}
+	n := copy(b, m.out)
+	m.out = m.out[n:]
+	return n, nil
+}
+
+// mask masks buf in place from the resume index, and returns the bytes that are ready to be read.
+// The remaining bytes are kept in hold.
+func (m *masker) mask(buf []byte) []byte {
+	i := m.resume
+	cut := len(buf)
+loop:
+	for ; i < len(buf); i++ {
+		c := buf[i]
+		cr := m.cr
+		m.cr = c == '\r'
+		switch m.state {
+		case maskNormal:
+			if isSpace(c) {
+				if c == '\r' || c == '\n' && !cr {
+					m.list.lineBreak()
+				}
+				continue
+			}
+			if c == '/' && m.comments {
+				if i == len(buf)-1 {
+					if m.err == nil {
+						cut = i
+						break loop
+					}
+					continue
+				}
+				switch buf[i+1] {
+				case '/':
+					m.state = maskLineComment
+				case '*':
+					m.state = maskBlockComment
+				}
+				if m.state != maskNormal {
+					m.comment = m.offset + int64(i)
+					m.list.begin(m.offset+int64(i), buf[i:i+2])
+					buf[i], buf[i+1] = ' ', ' '
+					i++
+					continue
+				}
+			}
+			m.list.significant(m.offset+int64(i), c)
+			if m.comma != -1 {
+				if c == '}' || c == ']' {
+					buf[m.comma] = ' '
+				}
+				m.comma = -1
+			}
+			switch {
+			case c == '"' || c == '\'' && m.singleQuotes:
+				m.state = maskString
+				m.quote = c
+			case c == ',':
+				// Only a comma after a value or member can be a trailing one, so that e.g. "[,]" is still rejected
+				if m.trailingCommas && m.last != 0 && bytes.IndexByte([]byte("[{,:"), m.last) < 0 {
+					m.comma = i
+				}
+			}
+			m.last = c
+		case maskString:
+			switch c {
+			case '\\':
+				m.state = maskStringEscape
+			case m.quote:
+				m.state = maskNormal
+				m.last = c
+				m.list.significant(m.offset+int64(i), c)
+			}
+		case maskStringEscape:
+			m.state = maskString
+		case maskLineComment:
+			// A line comment ends at any line break, i.e. LF, CR or CRLF
+			if c == '\r' || c == '\n' {
+				m.state = maskNormal
+				m.list.end()
+				m.list.lineBreak()
+				continue
+			}
+			m.list.write(c)
+			buf[i] = ' '
+		case maskBlockComment, maskBlockCommentStar:
+			m.list.write(c)
+			switch {
+			case c == '/' && m.state == maskBlockCommentStar:
+				m.state = maskNormal
+				m.list.end()
+			case c == '*':
+				m.state = maskBlockCommentStar
+			default:
+				m.state = maskBlockComment
+			}
+			if c != '\n' {
+				buf[i] = ' '
+			}
+		}
+	}
+	if m.err != nil {
+		switch m.state {
+		case maskLineComment:
+			m.list.end()
+		case maskBlockComment, maskBlockCommentStar:
+			if m.err == io.EOF {
+				m.err = &maskError{msg: "unterminated block comment", offset: m.comment}
+			}
+		}
+		m.offset += int64(len(buf))
+		m.hold, m.resume, m.comma = nil, 0, -1
+		return buf
+	}
+	if m.comma != -1 && m.comma < cut {
+		cut = m.comma
+	}
+	m.offset += int64(cut)
+	m.hold = append([]byte(nil), buf[cut:]...)
+	m.resume = i - cut
+	if m.comma != -1 {
+		m.comma -= cut
+	}
+	return buf[:cut]
+}
+
+// maskError is a syntax error of the masked parts of the document, whose offset is where the offending part begins.
+type maskError struct {
+	msg    string
+	offset int64
+}
+
+func (e *maskError) Error() string {
+	return e.msg
+}
+
+func (e *maskError) ErrorOffset() int64 {
+	return e.offset
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// bomMasker replaces the leading UTF-8 byte order mark (BOM) of the document read from r with spaces.
+type bomMasker struct {
+	r       io.Reader
+	checked bool
+}
+
+func (m *bomMasker) Read(b []byte) (int, error) {
+	if !m.checked {
+		m.checked = true
+		head := make([]byte, len(bom))
+		n, err := io.ReadFull(m.r, head)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return 0, err
+		}
+		head = head[:n]
+		if bytes.Equal(head, bom) {
+			head = bytes.Repeat([]byte{' '}, len(bom))
+		}
+		m.r = io.MultiReader(bytes.NewReader(head), m.r)
+	}
+	return m.r.Read(b)
+}
@@ -0,0 +1,31 @@
+package jsonpointerpos
+
+import (
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// GetPositionsNDJSON is like GetPositions, but for a stream of JSON documents separated by whitespace, e.g. the
+// newline delimited JSON (NDJSON) with one document per line. It returns the positions for each document in order,
+// which are relative to the whole stream. The blank lines in between or at the end are skipped.
+func GetPositionsNDJSON(document string, ptrs []jsonpointer.Pointer, opts ...Option) ([]map[string]JSONPointerPosition, error) {
+	w := newWalker(strings.NewReader(document), newOptions(opts))
+	w.exhaustive = true
+	ptrs = w.pos.opts.patterns(ptrs)
+	var out []map[string]JSONPointerPosition
+	for w.dec.More() {
+		tree := buildTokenTree(ptrs)
+		m, err := w.positions(&tree, ptrs)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, m)
+		// The positions of a document are not referenced afterwards
+		w.pos.positions = map[int64]Position{}
+		if w.pos.runeIndexes != nil {
+			w.pos.runeIndexes = map[int64]int64{}
+		}
+	}
+	return out, nil
+}
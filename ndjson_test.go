@@ -0,0 +1,64 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPositionsNDJSON(t *testing.T) {
+	input := "{\"a\": 1}\n{\"b\": {\"c\": 2}}\n\n{\"a\": [3]}\n\n"
+	ptrs := []jsonpointer.Pointer{mustPointer("/a"), mustPointer("/b/c")}
+
+	out, err := GetPositionsNDJSON(input, ptrs)
+	require.NoError(t, err)
+	require.Equal(t, []map[string]JSONPointerPosition{
+		{
+			"/a": {
+				Ptr:            mustPointer("/a"),
+				Depth:          1,
+				Kind:           KindNumber,
+				Position:       Position{Line: 1, Column: 7, Offset: 6},
+				EndPosition:    Position{Line: 1, Column: 7, Offset: 6},
+				ByteLength:     1,
+				KeyPosition:    &Position{Line: 1, Column: 2, Offset: 1},
+				KeyEndPosition: &Position{Line: 1, Column: 4, Offset: 3},
+				ColonPosition:  &Position{Line: 1, Column: 5, Offset: 4},
+				ParentPosition: &Position{Line: 1, Column: 1, Offset: 0},
+			},
+		},
+		{
+			"/b/c": {
+				Ptr:            mustPointer("/b/c"),
+				Depth:          2,
+				Kind:           KindNumber,
+				Position:       Position{Line: 2, Column: 13, Offset: 21},
+				EndPosition:    Position{Line: 2, Column: 13, Offset: 21},
+				ByteLength:     1,
+				KeyPosition:    &Position{Line: 2, Column: 8, Offset: 16},
+				KeyEndPosition: &Position{Line: 2, Column: 10, Offset: 18},
+				ColonPosition:  &Position{Line: 2, Column: 11, Offset: 19},
+				ParentPosition: &Position{Line: 2, Column: 7, Offset: 15},
+			},
+		},
+		{
+			"/a": {
+				Ptr:            mustPointer("/a"),
+				Depth:          1,
+				Kind:           KindArray,
+				Position:       Position{Line: 4, Column: 7, Offset: 32},
+				EndPosition:    Position{Line: 4, Column: 9, Offset: 34},
+				ClosePosition:  &Position{Line: 4, Column: 9, Offset: 34},
+				ByteLength:     3,
+				KeyPosition:    &Position{Line: 4, Column: 2, Offset: 27},
+				KeyEndPosition: &Position{Line: 4, Column: 4, Offset: 29},
+				ColonPosition:  &Position{Line: 4, Column: 5, Offset: 30},
+				ParentPosition: &Position{Line: 4, Column: 1, Offset: 26},
+			},
+		},
+	}, out)
+
+	_, err = GetPositionsNDJSON("{\"a\": 1}\n{\"a\": }\n", ptrs)
+	require.Error(t, err)
+}
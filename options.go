@@ -0,0 +1,426 @@
+package jsonpointerpos
+
+import "io"
+
+// Option configures how the positions are computed. All the functions accepting options behave the same as before
+// when no option is specified.
+type Option func(*options)
+
+type columnUnit int
+
+const (
+	// columnRune counts columns in Unicode code points.
+	columnRune columnUnit = iota
+	// columnUTF16 counts columns in UTF-16 code units.
+	columnUTF16
+	// columnDisplay counts columns in terminal display cells.
+	columnDisplay
+)
+
+// options is the configuration built from the Options. Its zero value is not valid, use newOptions instead.
+type options struct {
+	// columnUnit is the unit of the columns.
+	columnUnit columnUnit
+	// tabWidth is the number of columns that a tab expands to the multiple of.
+	tabWidth int
+	// zeroBased makes the lines and columns start at 0.
+	zeroBased bool
+	// unicodeLineTerminators also counts U+2028 and U+2029 as line breaks.
+	unicodeLineTerminators bool
+	// comments allows comments in the document.
+	comments bool
+	// trailingCommas allows trailing commas in objects and arrays.
+	trailingCommas bool
+	// raw captures the raw text of the values.
+	raw bool
+	// duplicateKeys finds the values of all the occurrences of duplicate object keys.
+	duplicateKeys bool
+	// firstDuplicateKey reports the first occurrence of duplicate object keys, instead of the last one.
+	firstDuplicateKey bool
+	// strictDuplicateKeys rejects the documents with duplicate object keys.
+	strictDuplicateKeys bool
+	// runeLength counts the length of the values in runes.
+	runeLength bool
+	// lineText reports the source text of the line that each value starts on.
+	lineText bool
+	// maxDepth, if positive, is the maximum nesting depth of the objects/arrays.
+	maxDepth int
+	// wildcards treats the reference tokens "*" and "**" as wildcards.
+	wildcards bool
+	// maxMatches, if positive, is the maximum number of the values matched by the wildcards in the result.
+	maxMatches int
+	// reportMissing includes the missing pointers in the result.
+	reportMissing bool
+	// errorOnMissing returns an error if any pointer doesn't exist in the document.
+	errorOnMissing bool
+	// partial returns the positions found so far along with an error, if the document is truncated.
+	partial bool
+	// recover skips the malformed objects/arrays, and returns the positions of the rest along with their errors.
+	recover bool
+	// ancestors reports the deepest existing ancestors of the missing pointers.
+	ancestors bool
+	// insertPositions reports where the missing pointers can be inserted.
+	insertPositions bool
+	// expandObjects includes the members of the objects that the pointers point to in the result.
+	expandObjects bool
+	// expandArrays includes the elements of the arrays that the pointers point to in the result.
+	expandArrays bool
+	// allowTrailing ignores the content after the top-level value.
+	allowTrailing bool
+	// caseInsensitiveKeys matches the object keys with the reference tokens case-insensitively.
+	caseInsensitiveKeys bool
+	// tokenReader creates the TokenReader instead of *json.Decoder.
+	tokenReader func(io.Reader) TokenReader
+	// json5 accepts the JSON5 syntax.
+	json5 bool
+	// base, if not nil, is the position of the start of the document within an enclosing file.
+	base *Position
+}
+
+// start returns the line and column of the start of the document, which are 1-based regardless of zeroBased.
+func (o options) start() (line, column int) {
+	switch {
+	case o.base == nil:
+		return 1, 1
+	case o.zeroBased:
+		return o.base.Line + 1, o.base.Column + 1
+	default:
+		return o.base.Line, o.base.Column
+	}
+}
+
+// baseOffset returns the offset of the start of the document within an enclosing file.
+func (o options) baseOffset() int64 {
+	if o.base == nil {
+		return 0
+	}
+	return o.base.Offset
+}
+
+// newOptions returns the default options, with the specified options applied in order. Nil options are ignored.
+func newOptions(opts []Option) options {
+	o := options{
+		tabWidth: 1,
+	}
+	for _, opt := range opts {
+		if opt == nil {
+			continue
+		}
+		opt(&o)
+	}
+	return o
+}
+
+// WithUTF16Columns counts the columns in UTF-16 code units, as is required by the Language Server Protocol.
+// Characters beyond the Basic Multilingual Plane (e.g. emoji) occupy two columns.
+func WithUTF16Columns() Option {
+	return func(o *options) {
+		o.columnUnit = columnUTF16
+	}
+}
+
+// WithDisplayWidthColumns counts the columns in terminal display cells, so that a caret printed under the column lines
+// up with the value (e.g. by Snippet). East Asian wide characters (e.g. CJK and most emoji) occupy two columns, and the
+// combining marks and other zero-width characters occupy none.
+func WithDisplayWidthColumns() Option {
+	return func(o *options) {
+		o.columnUnit = columnDisplay
+	}
+}
+
+// WithTabWidth expands each tab to the next multiple of n columns. The default tab width is 1.
+func WithTabWidth(n int) Option {
+	return func(o *options) {
+		if n < 1 {
+			n = 1
+		}
+		o.tabWidth = n
+	}
+}
+
+// WithZeroBased makes both the lines and columns of all the reported positions start at 0, instead of 1.
+func WithZeroBased() Option {
+	return func(o *options) {
+		o.zeroBased = true
+	}
+}
+
+// WithUnicodeLineTerminators also counts the Unicode line separator (U+2028) and paragraph separator (U+2029) as line
+// breaks, as JavaScript does. By default only LF, CR and CRLF are, which matches most editors and tools.
+func WithUnicodeLineTerminators() Option {
+	return func(o *options) {
+		o.unicodeLineTerminators = true
+	}
+}
+
+// WithComments allows the document to contain comments, i.e. line comments ("//") and block comments ("/* */").
+// The reported positions still point into the original document with the comments.
+func WithComments() Option {
+	return func(o *options) {
+		o.comments = true
+	}
+}
+
+// WithTrailingCommas allows the objects and arrays of the document to have trailing commas, e.g. `[1, 2,]`.
+func WithTrailingCommas() Option {
+	return func(o *options) {
+		o.trailingCommas = true
+	}
+}
+
+// WithRaw sets the raw source text of each value to the result.
+func WithRaw() Option {
+	return func(o *options) {
+		o.raw = true
+	}
+}
+
+// WithDuplicateKeys finds the values of all the occurrences of duplicate object keys, whose positions are reported in
+// the DuplicatePositions of the result. By default, only the reported occurrence is found.
+func WithDuplicateKeys() Option {
+	return func(o *options) {
+		o.duplicateKeys = true
+	}
+}
+
+// WithFirstDuplicateKey reports the first occurrence of duplicate object keys, instead of the last one as
+// encoding/json decodes. The walk can then stop once all the pointers are found (see WithAllowTrailing), while by
+// default, a value within an object is only final once the outermost object ends, as its key (or the key of any
+// enclosing member) might still be repeated.
+func WithFirstDuplicateKey() Option {
+	return func(o *options) {
+		o.firstDuplicateKey = true
+	}
+}
+
+// WithStrictDuplicateKeys rejects the documents that have any object with repeated keys, by returning a
+// *DuplicateKeyError with the position of the second occurrence of the key. The whole document is walked to check all
+// the objects, including those that no pointer points into. The keys are compared exactly, regardless of
+// WithCaseInsensitiveKeys.
+func WithStrictDuplicateKeys() Option {
+	return func(o *options) {
+		o.strictDuplicateKeys = true
+	}
+}
+
+// WithRuneLength reports the length of each value in runes (Unicode code points), in the RuneLength of the result.
+func WithRuneLength() Option {
+	return func(o *options) {
+		o.runeLength = true
+	}
+}
+
+// WithLineText reports the source text of the line that each value starts on, in the LineText of the result. The
+// lines are kept as they are scanned, so that it also works with GetPositionsReader, where the rest of the line after
+// the top-level value is only included as far as it has been read.
+func WithLineText() Option {
+	return func(o *options) {
+		o.lineText = true
+	}
+}
+
+// WithMaxDepth limits the nesting depth of the objects/arrays in the document to n, where the root object/array is
+// at depth 1. A *MaxDepthError is returned if the limit is exceeded. Non-positive n means the default limit of 10000,
+// which is the same as encoding/json and applies whatever the TokenReader is, e.g. with WithJSON5, so that the walk
+// can't exhaust the memory. A TokenReader might also have a limit of its own, e.g. *json.Decoder rejects the
+// documents nested deeper than 10000 with a syntax error instead.
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithWildcards treats the reference tokens "*" and "**" of the pointers as wildcards, instead of the literal keys.
+//
+// A reference token of "*" is a wildcard, which matches any object key or array index at that level. The pointers
+// containing wildcards are expanded to the matched pointers in the result, which can thus contain more entries than
+// the specified pointers. The matches can be limited to the first ones in document order with WithMaxMatches.
+//
+// A reference token of "**" is a recursive wildcard, which matches zero or more levels of object keys or array
+// indexes, e.g. "/**/name" matches all the "name" members at any depth. Each value is still walked only once.
+//
+// The keys "*" and "**" can't be pointed to with this option, though the wildcards match them as any other key.
+func WithWildcards() Option {
+	return func(o *options) {
+		o.wildcards = true
+	}
+}
+
+// withoutWildcards overrides WithWildcards, for the pointers that are always literal.
+func withoutWildcards() Option {
+	return func(o *options) {
+		o.wildcards = false
+	}
+}
+
+// WithMaxMatches limits the values matched by the wildcards ("*" and "**") to the first n of them in document order,
+// i.e. ordered by the offsets where they begin, in total across all the pointers with wildcards. An object/array thus
+// precedes its members/elements. Once the limit is reached, the rest of the document is only walked as far as the
+// pointers without wildcards require, so that sampling a huge array stops early with WithAllowTrailing, while it's
+// otherwise only drained to validate it. The pointers without wildcards are not limited, though they count towards
+// the limit if they are matched by a wildcard as well. Non-positive n means no limit, which is the default.
+func WithMaxMatches(n int) Option {
+	return func(o *options) {
+		o.maxMatches = n
+	}
+}
+
+// WithReportMissing includes the pointers that don't exist in the document in the result, with Missing set to true,
+// instead of omitting them. The pointers containing wildcards are never reported as missing. A pointer that descends
+// into a scalar value is reported with an Err of *ErrPointerTraversesScalar.
+func WithReportMissing() Option {
+	return func(o *options) {
+		o.reportMissing = true
+	}
+}
+
+// WithErrorOnMissing returns an *ErrPointerNotFound listing all the pointers that don't exist in the document, instead
+// of the result, if there is any. The pointers containing wildcards are never regarded as missing, even if they match
+// nothing.
+func WithErrorOnMissing() Option {
+	return func(o *options) {
+		o.errorOnMissing = true
+	}
+}
+
+// WithPartial returns the positions of the values that are complete before the end of a truncated document, e.g. a
+// file that is still being written, along with a *ParseError whose Err is ErrTruncated, instead of only the error.
+// The objects/arrays that are not closed are omitted, as well as their missing members/elements, which are reported
+// without a MissingReason or an Ancestor by WithReportMissing, and are never regarded as missing by
+// WithErrorOnMissing. The other malformed documents still result in only the error.
+func WithPartial() Option {
+	return func(o *options) {
+		o.partial = true
+	}
+}
+
+// WithRecover skips the malformed objects/arrays within the top-level value, and continues with their siblings,
+// instead of failing the whole document, e.g. for a best-effort indexing of messy data. The positions of the rest are
+// returned along with a *RecoveredError, which holds the *ParseError of each skipped object/array. A skipped value,
+// which is the innermost object/array that contains the position of the error, is omitted from the result along with
+// everything within it, as if it doesn't exist. The errors that can't be recovered from, e.g. within the top-level
+// value itself or an object/array that is never closed, are still returned without the result.
+//
+// As the document is walked again after each skipped value, it's read into memory by GetPositionsReader. It's
+// supported by GetPositions, GetPositionsReader, GetPositionsBytes and GetAllPositions, and ignored by the others.
+func WithRecover() Option {
+	return func(o *options) {
+		o.recover = true
+	}
+}
+
+// WithExpandObjects includes the members of the objects that the pointers point to in the result as well, e.g. "/a"
+// results in "/a/b" and "/a/c" in addition to "/a" for the document {"a": {"b": 1, "c": 2}}. The pointers to the
+// other values, including arrays, are not expanded.
+func WithExpandObjects() Option {
+	return func(o *options) {
+		o.expandObjects = true
+	}
+}
+
+// WithExpandArrays includes the elements of the arrays that the pointers point to in the result as well, keyed by
+// the pointers with the decimal indexes appended, e.g. "/items" results in "/items/0" and "/items/1" in addition to
+// "/items" for the document {"items": [true, false]}. The pointers to the other values are not expanded, unless
+// WithExpandObjects is specified for the objects.
+func WithExpandArrays() Option {
+	return func(o *options) {
+		o.expandArrays = true
+	}
+}
+
+// expands reports whether the children of the node are all requested, as the node is to be expanded by
+// WithExpandObjects or WithExpandArrays.
+func (o options) expands(node *tokenTree) bool {
+	if !node.expand {
+		return false
+	}
+	return node.delim == '{' && o.expandObjects || node.delim == '[' && o.expandArrays
+}
+
+// WithAllowTrailing ignores any content after the top-level value, e.g. a second value or some garbage. By default, a
+// *ParseError is returned for such content, and the whole document is always validated, even if all the pointers are
+// found before its end. With this option, the walk stops once all the pointers are found instead, without validating
+// the rest of the document, so that a malformed document might be accepted depending on the pointers.
+func WithAllowTrailing() Option {
+	return func(o *options) {
+		o.allowTrailing = true
+	}
+}
+
+// WithTokenReader walks the document with the TokenReader created by newReader, instead of *json.Decoder. The reader
+// passed to newReader is the document with the byte order mark, and the comments and the trailing commas allowed by
+// the options, replaced by whitespaces, so that the TokenReader only needs to handle the standard JSON, and the
+// offsets it reports still match the document.
+func WithTokenReader(newReader func(r io.Reader) TokenReader) Option {
+	return func(o *options) {
+		o.tokenReader = newReader
+	}
+}
+
+// WithCaseInsensitiveKeys matches the object keys with the reference tokens of the pointers case-insensitively, under
+// Unicode case-folding, e.g. "/Port" matches the key "port". The results are still keyed by the specified pointers.
+// If several keys of an object match the same token, e.g. "port" and "PORT", the last one is found like a duplicate
+// key (or the first one with WithFirstDuplicateKey), and all of them are reported with the WithDuplicateKeys option.
+// If several tokens differ only in case, a key matches the one of the same case, or otherwise the least one in byte
+// order.
+func WithCaseInsensitiveKeys() Option {
+	return func(o *options) {
+		o.caseInsensitiveKeys = true
+	}
+}
+
+// WithBaseOffset reports the positions relative to an enclosing file, which the document is embedded in at the byte
+// offset, line and column, e.g. a code block of a Markdown file. The line and column are zero-based with the
+// WithZeroBased option. The columns of the first line are counted from the base column, including the tab stops,
+// while the following lines start from the first column as usual. The offsets taken by OffsetToPosition,
+// PositionToOffset and LineIndex are relative to the enclosing file as well.
+func WithBaseOffset(offset int64, line, column int) Option {
+	return func(o *options) {
+		o.base = &Position{Line: line, Column: column, Offset: offset}
+	}
+}
+
+// WithJSON5 accepts the documents in the JSON5 syntax, which is a superset of JSON for the human-edited configurations.
+// It implies WithComments and WithTrailingCommas, and additionally accepts:
+//   - the object keys that are unquoted identifiers, e.g. {port: 8080}, whose KeyPosition is the first character of
+//     the identifier. The keys are matched against the reference tokens of the pointers as is.
+//   - the single-quoted strings for both the keys and the values, e.g. {'a': 'b'}, whose positions start from the
+//     opening single quote. Both kinds of strings can contain the escapes of ECMAScript, e.g. "\x41", and the line
+//     continuations.
+//   - the numbers Infinity, -Infinity and NaN (optionally with a sign), the hexadecimal integers, e.g. 0x1F, the
+//     numbers with a leading plus sign or a leading or trailing decimal point, e.g. +1, .5 and 5., which are
+//     reported like the other numbers, and decoded as json.Number of their source text by GetPositionsWithValues.
+//
+// It's ignored if WithTokenReader is specified.
+func WithJSON5() Option {
+	return func(o *options) {
+		o.json5 = true
+		o.comments = true
+		o.trailingCommas = true
+	}
+}
+
+// WithInsertPositions reports where the missing pointers can be inserted, e.g. for a quick fix that adds the missing
+// field. It implies WithAncestors, and sets the InsertPosition of each missing pointer along with its Ancestor. The
+// Ancestor is the deepest value along the pointer that exists, so for a gap of several levels, e.g. "/a/b/c" where only
+// "/a" exists, the Ancestor is "/a", and the intermediate values (i.e. "b") are to be inserted along with the missing
+// one. The InsertPosition is the closing delimiter of the Ancestor, i.e. right after its last member/element, so that a
+// comma is required before the inserted content unless the Ancestor is empty.
+func WithInsertPositions() Option {
+	return func(o *options) {
+		o.reportMissing = true
+		o.ancestors = true
+		o.insertPositions = true
+	}
+}
+
+// WithAncestors reports the deepest existing ancestor of each missing pointer, e.g. for an error message like "field c
+// not found under /a" for the missing "/a/b/c". It implies WithReportMissing, and sets the Ancestor and the
+// AncestorPosition of each missing pointer, where the Ancestor is the root pointer if even the first reference token
+// doesn't exist.
+func WithAncestors() Option {
+	return func(o *options) {
+		o.reportMissing = true
+		o.ancestors = true
+	}
+}
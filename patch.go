@@ -0,0 +1,81 @@
+package jsonpointerpos
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// PatchTarget is where an operation of a JSON Patch (RFC 6902) points to within the document.
+type PatchTarget struct {
+	// Op is the operation, e.g. "add" or "move".
+	Op string
+	// Path is the position of the "path" of the operation. It is Missing if the path doesn't exist, e.g. for an "add"
+	// of a new object member, while the "-" of an "add" to the end of an array is resolved to the insertion point.
+	Path JSONPointerPosition
+	// From is the position of the "from" of a "move" or "copy" operation, or nil for the other operations.
+	From *JSONPointerPosition
+}
+
+// PatchTargetPositions returns the positions that the operations of the JSON Patch point to within the document, in
+// the order of the operations, e.g. to report the conflicts before applying the patch. The patch is only parsed for
+// the "op", "path" and "from" of each operation, and is not applied. The missing paths are always reported, as if
+// WithReportMissing is specified.
+//
+// WithWildcards is ignored, as the paths of JSON Patch are always literal, e.g. "/*" points to the member "*".
+func PatchTargetPositions(document string, patch []byte, opts ...Option) ([]PatchTarget, error) {
+	var ops []struct {
+		Op   string  `json:"op"`
+		Path *string `json:"path"`
+		From *string `json:"from"`
+	}
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("invalid JSON Patch: %v", err)
+	}
+	type target struct {
+		path jsonpointer.Pointer
+		from *jsonpointer.Pointer
+	}
+	targets := make([]target, len(ops))
+	var ptrs []jsonpointer.Pointer
+	for i, op := range ops {
+		if op.Path == nil {
+			return nil, fmt.Errorf("invalid JSON Patch: operation %d: missing path", i)
+		}
+		ptr, err := jsonpointer.New(*op.Path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON Patch: operation %d: invalid path: %v", i, err)
+		}
+		targets[i].path = ptr
+		ptrs = append(ptrs, ptr)
+		if op.From == nil || op.Op != "move" && op.Op != "copy" {
+			continue
+		}
+		from, err := jsonpointer.New(*op.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON Patch: operation %d: invalid from: %v", i, err)
+		}
+		targets[i].from = &from
+		ptrs = append(ptrs, from)
+	}
+	m, err := GetPositions(document, ptrs, append(opts[:len(opts):len(opts)], WithReportMissing(), withoutWildcards())...)
+	if err != nil {
+		return nil, err
+	}
+	position := func(ptr jsonpointer.Pointer) JSONPointerPosition {
+		if pos, ok := m[ptr.String()]; ok {
+			return pos
+		}
+		return JSONPointerPosition{Ptr: ptr, Depth: len(ptr.DecodedTokens()), Missing: true}
+	}
+	out := make([]PatchTarget, len(ops))
+	for i, op := range ops {
+		out[i] = PatchTarget{Op: op.Op, Path: position(targets[i].path)}
+		if targets[i].from != nil {
+			from := position(*targets[i].from)
+			out[i].From = &from
+		}
+	}
+	return out, nil
+}
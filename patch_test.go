@@ -0,0 +1,46 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchTargetPositions(t *testing.T) {
+	input := `{"a": [1, 2], "b": {"c": true}}`
+	patch := `[
+  {"op": "replace", "path": "/a/0", "value": 3},
+  {"op": "add", "path": "/a/-", "value": 4},
+  {"op": "move", "from": "/b/c", "path": "/d"},
+  {"op": "test", "path": "", "value": {}, "from": "/ignored"}
+]`
+	out, err := PatchTargetPositions(input, []byte(patch))
+	require.NoError(t, err)
+	require.Len(t, out, 4)
+
+	require.Equal(t, "replace", out[0].Op)
+	require.Equal(t, Position{Line: 1, Column: 8, Offset: 7}, out[0].Path.Position)
+	require.Nil(t, out[0].From)
+
+	// The append target is the insertion point after the last element
+	require.Equal(t, Position{Line: 1, Column: 12, Offset: 11}, out[1].Path.Position)
+	require.Equal(t, 0, out[1].Path.ByteLength)
+
+	require.True(t, out[2].Path.Missing)
+	require.Equal(t, MissingKey, out[2].Path.MissingReason)
+	require.Equal(t, Position{Line: 1, Column: 26, Offset: 25}, out[2].From.Position)
+
+	require.Equal(t, Position{Line: 1, Column: 1, Offset: 0}, out[3].Path.Position)
+	require.Nil(t, out[3].From)
+
+	// The paths are literal, even with WithWildcards
+	out, err = PatchTargetPositions(`{"*": 1, "a": 2}`, []byte(`[{"op": "remove", "path": "/*"}]`), WithWildcards())
+	require.NoError(t, err)
+	require.False(t, out[0].Path.Missing)
+	require.Equal(t, Position{Line: 1, Column: 7, Offset: 6}, out[0].Path.Position)
+
+	for _, patch := range []string{`{}`, `[{"op": "remove"}]`, `[{"op": "add", "path": "a"}]`, `[{"op": "copy", "path": "/a", "from": "b"}]`} {
+		_, err := PatchTargetPositions(input, []byte(patch))
+		require.Error(t, err, patch)
+	}
+}
@@ -0,0 +1,44 @@
+package jsonpointerpos
+
+import "github.com/go-openapi/jsonpointer"
+
+// PathParser parses the paths of a path language into JSON pointers, so that the values can be specified in that
+// language by GetPositionsPaths.
+type PathParser interface {
+	Parse(path string) (jsonpointer.Pointer, error)
+}
+
+// PathParserFunc is a function that implements PathParser.
+type PathParserFunc func(path string) (jsonpointer.Pointer, error)
+
+func (f PathParserFunc) Parse(path string) (jsonpointer.Pointer, error) {
+	return f(path)
+}
+
+// The path languages that are supported out of the box.
+var (
+	// PointerParser parses the JSON pointers, see ParsePointer.
+	PointerParser PathParser = PathParserFunc(ParsePointer)
+	// DotPathParser parses the paths in the dot notation, see ParseDotPath.
+	DotPathParser PathParser = PathParserFunc(ParseDotPath)
+	// BracketPathParser parses the paths in the bracket notation, see ParseBracketPath. Unlike ParseBracketPath, the
+	// wildcards of the parsed pointers act as such without WithWildcards.
+	BracketPathParser PathParser = PathParserFunc(parseBracketPath)
+	// JSONPathParser parses the JSONPath expressions, see GetPositionsJSONPath. The wildcards of the parsed pointers act
+	// as such without WithWildcards.
+	JSONPathParser PathParser = PathParserFunc(parseJSONPath)
+)
+
+// GetPositionsPaths is like GetPositions, but the values are specified by the paths that are parsed by the parser.
+// The result is keyed by the JSON pointers of the paths.
+func GetPositionsPaths(document string, paths []string, parser PathParser, opts ...Option) (map[string]JSONPointerPosition, error) {
+	var ptrs []jsonpointer.Pointer
+	for _, path := range paths {
+		ptr, err := parser.Parse(path)
+		if err != nil {
+			return nil, err
+		}
+		ptrs = append(ptrs, ptr)
+	}
+	return GetPositions(document, ptrs, opts...)
+}
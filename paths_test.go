@@ -0,0 +1,41 @@
+package jsonpointerpos
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPositionsPaths(t *testing.T) {
+	input := `{"servers": [{"port": 80}]}`
+	cases := []struct {
+		name   string
+		path   string
+		parser PathParser
+	}{
+		{name: "pointer", path: "#/servers/0/port", parser: PointerParser},
+		{name: "dot", path: "servers.0.port", parser: DotPathParser},
+		{name: "bracket", path: `["servers"][0].port`, parser: BracketPathParser},
+		{name: "JSONPath", path: "$.servers[0].port", parser: JSONPathParser},
+		{
+			name: "custom",
+			path: "servers:0:port",
+			parser: PathParserFunc(func(path string) (jsonpointer.Pointer, error) {
+				return NewPointer(strings.Split(path, ":")...), nil
+			}),
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := GetPositionsPaths(input, []string{tt.path}, tt.parser)
+			require.NoError(t, err)
+			require.Equal(t, []string{"/servers/0/port"}, sortedKeys(out))
+			require.Equal(t, Position{Line: 1, Column: 23, Offset: 22}, out["/servers/0/port"].Position)
+		})
+	}
+
+	_, err := GetPositionsPaths(input, []string{"servers"}, PointerParser)
+	require.Error(t, err)
+}
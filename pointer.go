@@ -0,0 +1,34 @@
+package jsonpointerpos
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// ParsePointer parses a JSON pointer in either the string representation, e.g. "/a~1b", or the URI fragment
+// identifier representation, e.g. "#/a~1b" (RFC 6901, section 6), whose percent-encoded characters are decoded.
+// Both representations result in the same pointer, which is keyed by its string representation in the result of
+// GetPositions.
+func ParsePointer(s string) (jsonpointer.Pointer, error) {
+	if fragment, ok := strings.CutPrefix(s, "#"); ok {
+		unescaped, err := url.PathUnescape(fragment)
+		if err != nil {
+			return jsonpointer.Pointer{}, fmt.Errorf("invalid URI fragment %q: %v", s, err)
+		}
+		s = unescaped
+	}
+	return jsonpointer.New(s)
+}
+
+// NewPointer returns the JSON pointer of the reference tokens, which are escaped as needed, e.g. the token "a/b"
+// results in "/a~1b". It returns the root pointer if there is no token, while an empty token is the empty key of an
+// object, e.g. NewPointer("") is "/".
+func NewPointer(tokens ...string) jsonpointer.Pointer {
+	if ptr := newJSONPtr(tokens); ptr != nil {
+		return *ptr
+	}
+	return jsonpointer.Pointer{}
+}
@@ -0,0 +1,109 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePointer(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		expect string
+		err    bool
+	}{
+		{
+			name:   "string representation",
+			input:  "/a~01b/c",
+			expect: "/a~01b/c",
+		},
+		{
+			name:   "fragment",
+			input:  "#/a~01b/c",
+			expect: "/a~01b/c",
+		},
+		{
+			name:   "fragment with percent-encoding",
+			input:  "#/c%25d/e%20f/%E2%82%AC",
+			expect: "/c%d/e f/€",
+		},
+		{
+			name:   "root fragment",
+			input:  "#",
+			expect: "",
+		},
+		{
+			name:  "invalid percent-encoding",
+			input: "#/a%zz",
+			err:   true,
+		},
+		{
+			name:  "invalid start",
+			input: "#a",
+			err:   true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ptr, err := ParsePointer(tt.input)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, ptr.String())
+		})
+	}
+}
+
+func TestGetPositionsFragment(t *testing.T) {
+	input := `{"a~1b": {"c d": 1}}`
+	ptr, err := ParsePointer("#/a~01b/c%20d")
+	require.NoError(t, err)
+	out, err := GetPositions(input, []jsonpointer.Pointer{ptr})
+	require.NoError(t, err)
+	require.Equal(t, map[string]JSONPointerPosition{
+		"/a~01b/c d": {
+			Ptr:            ptr,
+			Depth:          2,
+			Kind:           KindNumber,
+			Position:       Position{Line: 1, Column: 18, Offset: 17},
+			EndPosition:    Position{Line: 1, Column: 18, Offset: 17},
+			ByteLength:     1,
+			KeyPosition:    &Position{Line: 1, Column: 11, Offset: 10},
+			KeyEndPosition: &Position{Line: 1, Column: 15, Offset: 14},
+			ColonPosition:  &Position{Line: 1, Column: 16, Offset: 15},
+			ParentPosition: &Position{Line: 1, Column: 10, Offset: 9},
+		},
+	}, out)
+}
+
+func TestNewPointer(t *testing.T) {
+	cases := []struct {
+		name   string
+		tokens []string
+		expect string
+	}{
+		{name: "root", tokens: nil, expect: ""},
+		{name: "empty key", tokens: []string{""}, expect: "/"},
+		{name: "slash and tilde", tokens: []string{"a/b", "~c", "0"}, expect: "/a~1b/~0c/0"},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ptr := NewPointer(tt.tokens...)
+			require.Equal(t, tt.expect, ptr.String())
+			require.Equal(t, len(tt.tokens), len(ptr.DecodedTokens()))
+			for i, tk := range ptr.DecodedTokens() {
+				require.Equal(t, tt.tokens[i], tk)
+			}
+		})
+	}
+
+	out, err := GetPositions(`{"a/b": {"~c": [1]}}`, []jsonpointer.Pointer{NewPointer("a/b", "~c", "0")})
+	require.NoError(t, err)
+	require.Equal(t, Position{Line: 1, Column: 17, Offset: 16}, out["/a~1b/~0c/0"].Position)
+}
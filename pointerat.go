@@ -0,0 +1,51 @@
+package jsonpointerpos
+
+import (
+	"github.com/go-openapi/jsonpointer"
+)
+
+// PointerAt returns the pointer of the innermost value within the document, whose span contains the position, i.e.
+// the reverse lookup of GetPositions. Only the Line and Column of the position are used, which are interpreted in
+// the same way as the positions returned with the same options.
+//
+// The span of an object member starts from its key, so a position on the key (or the colon) results in the pointer
+// of that member. A position on the whitespace between the values within an object/array results in the pointer of
+// that object/array. The returned bool reports whether the position is within the root value.
+func PointerAt(document string, pos Position, opts ...Option) (jsonpointer.Pointer, bool, error) {
+	m, err := GetAllPositions(document, opts...)
+	if err != nil {
+		return jsonpointer.Pointer{}, false, err
+	}
+	ptr, found := pointerAt(m, pos)
+	return ptr, found, nil
+}
+
+// pointerAt returns the pointer of the innermost value among all the positions m, whose span contains the position.
+func pointerAt(m map[string]JSONPointerPosition, pos Position) (jsonpointer.Pointer, bool) {
+	var (
+		found bool
+		ptr   jsonpointer.Pointer
+		depth int
+	)
+	for _, v := range m {
+		start := v.Position
+		if v.KeyPosition != nil {
+			start = *v.KeyPosition
+		}
+		if positionBefore(pos, start) || positionBefore(v.EndPosition, pos) {
+			continue
+		}
+		if d := len(v.Ptr.DecodedTokens()); !found || d > depth {
+			found, ptr, depth = true, v.Ptr, d
+		}
+	}
+	return ptr, found
+}
+
+// positionBefore reports whether the position a is before the position b, by comparing the lines and the columns.
+func positionBefore(a, b Position) bool {
+	if a.Line != b.Line {
+		return a.Line < b.Line
+	}
+	return a.Column < b.Column
+}
@@ -0,0 +1,96 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPointerAt(t *testing.T) {
+	input := `{
+  "a": {"b": [1, "xy"]},
+  "c": true
+}`
+
+	cases := []struct {
+		name   string
+		pos    Position
+		opts   []Option
+		expect string
+		ok     bool
+	}{
+		{
+			name:   "on the opening delimiter of the root",
+			pos:    Position{Line: 1, Column: 1},
+			expect: "",
+			ok:     true,
+		},
+		{
+			name:   "on a key",
+			pos:    Position{Line: 2, Column: 4},
+			expect: "/a",
+			ok:     true,
+		},
+		{
+			name:   "on a colon",
+			pos:    Position{Line: 2, Column: 6},
+			expect: "/a",
+			ok:     true,
+		},
+		{
+			name:   "on a nested key",
+			pos:    Position{Line: 2, Column: 10},
+			expect: "/a/b",
+			ok:     true,
+		},
+		{
+			name:   "on the whitespace within an array",
+			pos:    Position{Line: 2, Column: 17},
+			expect: "/a/b",
+			ok:     true,
+		},
+		{
+			name:   "on the last character of a string",
+			pos:    Position{Line: 2, Column: 21},
+			expect: "/a/b/1",
+			ok:     true,
+		},
+		{
+			name:   "on a scalar",
+			pos:    Position{Line: 3, Column: 10},
+			expect: "/c",
+			ok:     true,
+		},
+		{
+			name:   "between the members",
+			pos:    Position{Line: 2, Column: 1},
+			expect: "",
+			ok:     true,
+		},
+		{
+			name:   "zero-based",
+			pos:    Position{Line: 2, Column: 8},
+			opts:   []Option{WithZeroBased()},
+			expect: "/c",
+			ok:     true,
+		},
+		{
+			name: "after the root",
+			pos:  Position{Line: 4, Column: 2},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ptr, ok, err := PointerAt(input, tt.pos, tt.opts...)
+			require.NoError(t, err)
+			require.Equal(t, tt.ok, ok)
+			if ok {
+				require.Equal(t, tt.expect, ptr.String())
+			}
+		})
+	}
+
+	_, _, err := PointerAt(`{"a": }`, Position{Line: 1, Column: 1})
+	require.Error(t, err)
+}
@@ -0,0 +1,325 @@
+package jsonpointerpos
+
+import (
+	"bytes"
+	"io"
+	"unicode"
+	"unicode/utf8"
+)
+
+// positioner converts byte offsets of a document to positions by scanning the document sequentially.
+// It either wraps the reader of the document and only keeps the bytes that are read but not yet scanned, or
+// scans the whole document held in buf, in which case the reader is nil.
+// The offsets must be resolved in non-decreasing order, so that each byte is scanned once no matter how many offsets
+// are resolved, which is why GetPositions doesn't need a LineIndex.
+type positioner struct {
+	r    io.Reader
+	opts options
+	// buf is the read bytes that are not scanned yet, starting from offset.
+	buf    []byte
+	offset int64
+	line   int
+	column int
+	// cr indicates whether the last scanned rune is a carriage return.
+	cr bool
+	// sync, if not nil, returns an offset that all the offsets being resolved afterwards won't be less than.
+	// It allows the bytes before that offset to be scanned and dropped on each read.
+	sync func() int64
+	// positions caches the resolved positions, keyed by the offset.
+	positions map[int64]Position
+	// runes is the number of the scanned runes.
+	runes int64
+	// runeIndexes is the rune indexes of the resolved offsets, which is only filled in with the runeLength option.
+	runeIndexes map[int64]int64
+	// lineStarts is the offsets of the line starts of the resolved offsets, which is only filled in with the lineText
+	// option.
+	lineStarts map[int64]int64
+	// lineTexts is the texts of the scanned lines that contain any resolved offset, keyed by the offsets of their
+	// starts.
+	lineTexts map[int64]string
+	// curLine is the scanned bytes of the current line, which starts from curLineStart. pendingLine indicates
+	// whether the current line contains any resolved offset, and is to be kept in lineTexts once it ends.
+	curLine      []byte
+	curLineStart int64
+	pendingLine  bool
+	// captures is the number of the active captures, during which the scanned bytes are kept in kept.
+	captures int
+	kept     []byte
+	// keptOffset is the offset of kept[0].
+	keptOffset int64
+	// hold, if not negative, is an offset that the bytes from it are kept on sync, so that they can be searched by
+	// separator.
+	hold int64
+	// eof indicates whether the reader has reached the end of the document.
+	eof bool
+}
+
+func newPositioner(r io.Reader, opts options) *positioner {
+	line, column := opts.start()
+	p := &positioner{
+		r:         r,
+		opts:      opts,
+		line:      line,
+		column:    column,
+		positions: map[int64]Position{},
+		hold:      -1,
+	}
+	if opts.runeLength {
+		p.runeIndexes = map[int64]int64{}
+	}
+	if opts.lineText {
+		p.lineStarts = map[int64]int64{}
+		p.lineTexts = map[int64]string{}
+	}
+	return p
+}
+
+func (p *positioner) Read(b []byte) (int, error) {
+	if p.sync != nil {
+		offset := p.sync()
+		if p.hold >= 0 && p.hold < offset {
+			offset = p.hold
+		}
+		p.scan(offset)
+	}
+	n, err := p.r.Read(b)
+	p.buf = append(p.buf, b[:n]...)
+	if err == io.EOF {
+		p.eof = true
+	}
+	return n, err
+}
+
+// mark resolves the position of the specified offset and caches it.
+func (p *positioner) mark(offset int64) {
+	if _, ok := p.positions[offset]; ok {
+		return
+	}
+	p.positions[offset] = p.position(offset)
+	if p.runeIndexes != nil {
+		p.runeIndexes[offset] = p.runes
+	}
+	if p.lineStarts != nil {
+		p.lineStarts[offset] = p.curLineStart
+		p.pendingLine = true
+	}
+}
+
+// lineText returns the text of the line that contains the resolved offset, without the line break.
+func (p *positioner) lineText(offset int64) string {
+	start, ok := p.lineStarts[offset]
+	if !ok {
+		return ""
+	}
+	if text, ok := p.lineTexts[start]; ok {
+		return text
+	}
+	// The current line ends within the bytes that are not scanned yet
+	rest := p.buf
+	breaks := "\r\n"
+	if p.opts.unicodeLineTerminators {
+		breaks += "\u2028\u2029"
+	}
+	if i := bytes.IndexAny(rest, breaks); i >= 0 {
+		rest = rest[:i]
+	}
+	return string(p.curLine) + string(rest)
+}
+
+// capture starts a capture of the raw bytes from the specified offset, which is ended by captured.
+// The captures can be nested.
+func (p *positioner) capture(offset int64) {
+	p.scan(offset)
+	if p.captures == 0 {
+		p.kept = p.kept[:0]
+		p.keptOffset = offset
+	}
+	p.captures++
+}
+
+// captured ends a capture started by capture, and returns the raw bytes between the start and end offsets.
+func (p *positioner) captured(start, end int64) string {
+	p.scan(end)
+	raw := string(p.kept[start-p.keptOffset : end-p.keptOffset])
+	p.captures--
+	if p.captures == 0 {
+		p.kept = p.kept[:0]
+	}
+	return raw
+}
+
+// stringStart returns the offset of the opening quote of the string, whose closing quote is right before the end
+// offset. The quotes within the string are told apart by the odd number of the preceding backslashes. The string must
+// not be scanned yet.
+func (p *positioner) stringStart(end int64) int64 {
+	b := p.buf[:end-p.offset]
+	for i := len(b) - 2; i >= 0; i-- {
+		if b[i] != '"' {
+			continue
+		}
+		n := 0
+		for j := i - 1; j >= 0 && b[j] == '\\'; j-- {
+			n++
+		}
+		if n%2 == 0 {
+			return p.offset + int64(i)
+		}
+	}
+	return p.offset
+}
+
+// separator returns the offset of the separator c that follows the offset from, by skipping the whitespaces and the
+// comments in between. It returns -1 if the separator is not found in the buffered bytes. The offset must not be
+// scanned yet.
+func (p *positioner) separator(from int64, c byte) int64 {
+	offset := p.skipSpace(from)
+	if i := offset - p.offset; i < int64(len(p.buf)) && p.buf[i] == c {
+		return offset
+	}
+	return -1
+}
+
+// skipSpace returns the offset of the first byte from the offset from, which is neither a whitespace nor within a
+// comment, or the end of the buffered bytes. The offset must not be scanned yet.
+func (p *positioner) skipSpace(from int64) int64 {
+	b := p.buf[from-p.offset:]
+	for i := 0; i < len(b); i++ {
+		switch {
+		case b[i] == '/' && i+1 < len(b) && b[i+1] == '/':
+			for i < len(b) && b[i] != '\n' && b[i] != '\r' {
+				i++
+			}
+		case b[i] == '/' && i+1 < len(b) && b[i+1] == '*':
+			i += 2
+			for i+1 < len(b) && !(b[i] == '*' && b[i+1] == '/') {
+				i++
+			}
+			i++
+		case !isSpace(b[i]):
+			return from + int64(i)
+		}
+	}
+	return from + int64(len(b))
+}
+
+// position returns the position of the specified offset.
+func (p *positioner) position(offset int64) Position {
+	p.scan(offset)
+	pos := Position{
+		Line:   p.line,
+		Column: p.column,
+		Offset: offset + p.opts.baseOffset(),
+	}
+	if p.opts.zeroBased {
+		pos.Line--
+		pos.Column--
+	}
+	return pos
+}
+
+// scan scans the buffered bytes until the specified offset.
+func (p *positioner) scan(offset int64) {
+	var i int
+	for p.offset < offset && i < len(p.buf) {
+		r, size := utf8.DecodeRune(p.buf[i:])
+		i += size
+		p.offset += int64(size)
+		p.runes++
+		cr := p.cr
+		p.cr = r == '\r'
+		if p.lineStarts != nil {
+			p.scanLine(r, cr, p.buf[i-size:i])
+		}
+		switch r {
+		case '\uFEFF':
+			// The leading BOM occupies no column
+			if p.offset != int64(size) {
+				p.column += p.width(r)
+			}
+		case '\n':
+			// A line feed following a carriage return is part of the same line break
+			if !cr {
+				p.line++
+				p.column = 1
+			}
+		case '\r':
+			// Both CRLF and a lone CR are line breaks
+			p.line++
+			p.column = 1
+		case '\u2028', '\u2029':
+			if p.opts.unicodeLineTerminators {
+				p.line++
+				p.column = 1
+			} else {
+				p.column += p.width(r)
+			}
+		case '\t':
+			n := p.opts.tabWidth
+			p.column = (p.column-1)/n*n + n + 1
+		default:
+			p.column += p.width(r)
+		}
+	}
+	if p.captures > 0 {
+		p.kept = append(p.kept, p.buf[:i]...)
+	}
+	p.buf = p.buf[i:]
+}
+
+// scanLine keeps the scanned rune in the current line, which is ended by a line break.
+func (p *positioner) scanLine(r rune, cr bool, b []byte) {
+	switch {
+	case r == '\r' || r == '\n' && !cr || p.opts.unicodeLineTerminators && (r == '\u2028' || r == '\u2029'):
+		if p.pendingLine {
+			p.lineTexts[p.curLineStart] = string(p.curLine)
+			p.pendingLine = false
+		}
+		fallthrough
+	case r == '\n':
+		// The line feed of a CRLF only moves the start of the next line
+		p.curLine = p.curLine[:0]
+		p.curLineStart = p.offset
+	default:
+		p.curLine = append(p.curLine, b...)
+	}
+}
+
+// width returns the number of columns occupied by the rune.
+func (p *positioner) width(r rune) int {
+	switch p.opts.columnUnit {
+	case columnUTF16:
+		if r >= 0x10000 && r <= utf8.MaxRune {
+			return 2
+		}
+		return 1
+	case columnDisplay:
+		return runeWidth(r)
+	default:
+		return 1
+	}
+}
+
+// runeWidth returns the number of the display cells that the rune occupies in a terminal.
+func runeWidth(r rune) int {
+	switch {
+	case r == utf8.RuneError || r < 0x300:
+		return 1
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	// The wide ranges of Hangul Jamo, CJK to Yi, Hangul Syllables, CJK Compatibility Ideographs and Forms, Fullwidth
+	// Forms, the emoji blocks and CJK Extension B onwards
+	case r >= 0x1100 && r <= 0x115F,
+		r >= 0x2E80 && r <= 0xA4CF && r != 0x303F,
+		r >= 0xAC00 && r <= 0xD7A3,
+		r >= 0xF900 && r <= 0xFAFF,
+		r >= 0xFE30 && r <= 0xFE4F,
+		r >= 0xFF00 && r <= 0xFF60,
+		r >= 0xFFE0 && r <= 0xFFE6,
+		r >= 0x1F300 && r <= 0x1F64F,
+		r >= 0x1F900 && r <= 0x1F9FF,
+		r >= 0x20000 && r <= 0x3FFFD:
+		return 2
+	default:
+		return 1
+	}
+}
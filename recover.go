@@ -0,0 +1,133 @@
+package jsonpointerpos
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+)
+
+// RecoveredError is returned with the WithRecover option along with the positions, when any malformed object/array
+// is skipped.
+type RecoveredError struct {
+	// Errors is the errors of the skipped objects/arrays in the document order. With the WithPartial option, the last
+	// one can be the one of ErrTruncated instead.
+	Errors []*ParseError
+}
+
+func (e *RecoveredError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return "malformed values skipped: " + strings.Join(msgs, "; ")
+}
+
+func (e *RecoveredError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, err := range e.Errors {
+		errs[i] = err
+	}
+	return errs
+}
+
+// recoverPositions calls get with the walkers of the data, until it succeeds or fails with an error that can't be
+// recovered from. On each *ParseError, the innermost object/array that contains its position is masked as a number
+// for the next walk, which is then omitted from the result by prune.
+func recoverPositions(data []byte, opts options, get func(w *walker) (map[string]JSONPointerPosition, error)) (map[string]JSONPointerPosition, error) {
+	var masked []byte
+	var errs []*ParseError
+	skipped := map[int64]bool{}
+	for {
+		w := newBytesWalker(data, opts)
+		if masked != nil {
+			w.comments = newWalkerComments(opts)
+			w.dec = newTokenReader(bytes.NewReader(masked), opts, w.comments)
+		}
+		w.skipped = skipped
+		out, err := get(w)
+		var perr *ParseError
+		if err != nil && out == nil && errors.As(err, &perr) && !errors.Is(err, ErrTrailingContent) {
+			start, end, ok := enclosingContainer(data, perr.Position.Offset-opts.baseOffset(), opts)
+			if ok {
+				if masked == nil {
+					masked = append([]byte(nil), data...)
+				}
+				// The value is kept as a number at its end, so that the following separator is found as is
+				for i := start; i < end; i++ {
+					masked[i] = ' '
+				}
+				masked[end] = '0'
+				skipped[end] = true
+				errs = append(errs, perr)
+				continue
+			}
+		}
+		if err != nil && out == nil || len(errs) == 0 {
+			return out, err
+		}
+		if errors.As(err, &perr) {
+			errs = append(errs, perr)
+		}
+		return out, &RecoveredError{Errors: errs}
+	}
+}
+
+// enclosingContainer returns the offsets of the opening and the closing delimiters of the innermost object/array that
+// contains the offset, by matching the delimiters outside the strings (and the comments if allowed) of the data. The
+// returned bool is false if there is no such object/array except the top-level value, or it isn't closed.
+func enclosingContainer(data []byte, offset int64, opts options) (start, end int64, ok bool) {
+	var stack []int64
+	depth := -1
+	var quote byte
+	for i := int64(0); i < int64(len(data)); i++ {
+		// The offset might be skipped along with an escape or a comment, which doesn't change the stack
+		if depth < 0 && i >= offset {
+			if len(stack) < 2 {
+				return 0, 0, false
+			}
+			start = stack[len(stack)-1]
+			depth = len(stack) - 1
+		}
+		c := data[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' && opts.json5:
+			quote = c
+		case c == '/' && opts.comments && i+1 < int64(len(data)) && data[i+1] == '/':
+			for i+1 < int64(len(data)) && data[i+1] != '\n' && data[i+1] != '\r' {
+				i++
+			}
+		case c == '/' && opts.comments && i+1 < int64(len(data)) && data[i+1] == '*':
+			for i += 2; i+1 < int64(len(data)) && !(data[i] == '*' && data[i+1] == '/'); i++ {
+			}
+			i++
+		case c == '{' || c == '[':
+			stack = append(stack, i)
+		case c == '}' || c == ']':
+			if len(stack) == 0 {
+				return 0, 0, false
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == depth {
+				return start, i, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// prune unsets the offsets of the tree nodes of the values that are skipped by recoverPositions, so that they are
+// regarded as missing.
+func (tree *tokenTree) prune(skipped map[int64]bool) {
+	for _, child := range tree.children {
+		child.prune(skipped)
+	}
+	if tree.offset != nil && skipped[*tree.offset] {
+		*tree = tokenTree{tk: tree.tk, requested: tree.requested, expand: tree.expand, children: tree.children}
+	}
+}
@@ -0,0 +1,116 @@
+package jsonpointerpos
+
+import (
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPositionsRecover(t *testing.T) {
+	input := `{
+  "a": {"x" 1, "y": [2]},
+  "b": [1, 2 3],
+  "c": {"d": "e"}
+}`
+	ptrs := []jsonpointer.Pointer{mustPointer("/a/y/0"), mustPointer("/b"), mustPointer("/c/d")}
+	_, err := GetPositions(input, ptrs)
+	require.Error(t, err)
+
+	for _, get := range []func() (map[string]JSONPointerPosition, error){
+		func() (map[string]JSONPointerPosition, error) {
+			return GetPositions(input, ptrs, WithRecover(), WithReportMissing())
+		},
+		func() (map[string]JSONPointerPosition, error) {
+			return GetPositionsReader(iotest.OneByteReader(strings.NewReader(input)), ptrs, WithRecover(), WithReportMissing())
+		},
+	} {
+		out, err := get()
+		var rerr *RecoveredError
+		require.ErrorAs(t, err, &rerr)
+		require.Len(t, rerr.Errors, 2)
+		require.Equal(t, Position{Line: 2, Column: 13, Offset: 14}, rerr.Errors[0].Position)
+		require.Equal(t, Position{Line: 3, Column: 14, Offset: 41}, rerr.Errors[1].Position)
+
+		require.True(t, out["/a/y/0"].Missing)
+		require.True(t, out["/b"].Missing)
+		require.Equal(t, MissingKey, out["/b"].MissingReason)
+		require.Equal(t, Position{Line: 4, Column: 14, Offset: 58}, out["/c/d"].Position)
+		require.Equal(t, Position{Line: 4, Column: 9, Offset: 53}, *out["/c/d"].KeyPosition)
+		require.Equal(t, Position{Line: 4, Column: 8, Offset: 52}, *out["/c/d"].ParentPosition)
+	}
+
+	all, err := GetAllPositions(input, WithRecover())
+	require.Error(t, err)
+	require.Equal(t, []string{"", "/c", "/c/d"}, sortedKeys(all))
+	require.Equal(t, Position{Line: 3, Column: 16, Offset: 43}, *all["/c"].CommaPosition)
+}
+
+func TestGetPositionsRecoverDelimiters(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		opts   []Option
+		expect []string
+	}{
+		{
+			name:   "mismatched delimiter",
+			input:  `[[1}, 2]`,
+			expect: []string{"", "/1"},
+		},
+		{
+			name:   "delimiters within strings",
+			input:  `[{"a": "]}", "b" 1}, "[{"]`,
+			expect: []string{"", "/1"},
+		},
+		{
+			name:   "delimiters within comments",
+			input:  "[[1 /* ] */ x], // ]\n 2]",
+			opts:   []Option{WithComments()},
+			expect: []string{"", "/1"},
+		},
+		{
+			name:   "nested",
+			input:  `{"a": [{"b": x}, 1], "c": 2}`,
+			expect: []string{"", "/a", "/a/1", "/c"},
+		},
+		{
+			name:   "JSON5",
+			input:  `{a: ['}', 1 2], b: 3}`,
+			opts:   []Option{WithJSON5()},
+			expect: []string{"", "/b"},
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			all, err := GetAllPositions(tt.input, append(tt.opts, WithRecover())...)
+			var rerr *RecoveredError
+			require.ErrorAs(t, err, &rerr)
+			require.Len(t, rerr.Errors, 1)
+			require.Equal(t, tt.expect, sortedKeys(all))
+		})
+	}
+}
+
+func TestGetPositionsRecoverUnrecoverable(t *testing.T) {
+	for _, input := range []string{`{"a" 1}`, `[[1 2]`, `[1] 2`, `[[1, x]`} {
+		out, err := GetAllPositions(input, WithRecover())
+		require.Error(t, err, input)
+		require.Nil(t, out, input)
+	}
+
+	// The valid document results in no error
+	out, err := GetAllPositions(`[[1]]`, WithRecover())
+	require.NoError(t, err)
+	require.Len(t, out, 3)
+
+	// The truncation is reported along with the skipped values
+	out, err = GetAllPositions(`[[x], 1, [2`, WithRecover(), WithPartial())
+	var rerr *RecoveredError
+	require.ErrorAs(t, err, &rerr)
+	require.Len(t, rerr.Errors, 2)
+	require.ErrorIs(t, err, ErrTruncated)
+	require.Equal(t, []string{"/1", "/2/0"}, sortedKeys(out))
+}
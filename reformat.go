@@ -0,0 +1,29 @@
+package jsonpointerpos
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+)
+
+// Reformat pretty-prints the document with json.Indent, and returns the reformatted document along with the Document
+// of it, so that the positions of the values can be looked up in the reformatted document by the same pointers as the
+// original one, e.g. by PositionOf. The comments and the trailing commas that are allowed by the options are dropped,
+// while the JSON5 syntax (WithJSON5) is not supported. The options apply to the positions of the returned Document.
+func Reformat(document, prefix, indent string, opts ...Option) (string, *Document, error) {
+	src, err := io.ReadAll(maskReader(strings.NewReader(document), newOptions(opts), nil))
+	if err != nil {
+		return "", nil, err
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, src, prefix, indent); err != nil {
+		return "", nil, err
+	}
+	output := buf.String()
+	doc, err := Parse(output, opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	return output, doc, nil
+}
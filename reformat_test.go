@@ -0,0 +1,38 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReformat(t *testing.T) {
+	input := `{"a": [1, {"b": true}], // note
+"c": "x",}`
+	output, doc, err := Reformat(input, "", "  ", WithComments(), WithTrailingCommas())
+	require.NoError(t, err)
+	require.Equal(t, `{
+  "a": [
+    1,
+    {
+      "b": true
+    }
+  ],
+  "c": "x"
+}`, output)
+
+	orig, err := GetAllPositions(input, WithComments(), WithTrailingCommas())
+	require.NoError(t, err)
+	all := doc.AllPositions()
+	require.Equal(t, sortedKeys(orig), sortedKeys(all))
+
+	pos, ok := doc.PositionOf(mustPointer("/a/1/b"))
+	require.True(t, ok)
+	require.Equal(t, Position{Line: 5, Column: 12, Offset: 35}, pos.Position)
+	pos, ok = doc.PositionOf(mustPointer("/c"))
+	require.True(t, ok)
+	require.Equal(t, Position{Line: 8, Column: 8, Offset: 58}, pos.Position)
+
+	_, _, err = Reformat(input, "", "  ")
+	require.Error(t, err)
+}
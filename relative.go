@@ -0,0 +1,212 @@
+package jsonpointerpos
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// relativePointer is a parsed relative JSON pointer, as defined by draft-bhutton-relative-json-pointer.
+type relativePointer struct {
+	// up is the number of levels to move up from the base location.
+	up int
+	// index is the index manipulation applied to the array index that is moved to, if hasIndex is true.
+	index    int
+	hasIndex bool
+	// hash indicates that the key or index of the location is referenced, instead of its value.
+	hash bool
+	ptr  jsonpointer.Pointer
+}
+
+func parseRelativePointer(s string) (relativePointer, error) {
+	var rp relativePointer
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	if n == 0 {
+		return rp, fmt.Errorf("invalid relative JSON pointer %q: missing the leading non-negative integer", s)
+	}
+	if n > 1 && s[0] == '0' {
+		return rp, fmt.Errorf("invalid relative JSON pointer %q: leading zero", s)
+	}
+	up, err := strconv.Atoi(s[:n])
+	if err != nil {
+		return rp, fmt.Errorf("invalid relative JSON pointer %q: %v", s, err)
+	}
+	rp.up = up
+	rest := s[n:]
+
+	if len(rest) != 0 && (rest[0] == '+' || rest[0] == '-') {
+		m := 1
+		for m < len(rest) && rest[m] >= '0' && rest[m] <= '9' {
+			m++
+		}
+		if m == 1 || (m > 2 && rest[1] == '0') {
+			return rp, fmt.Errorf("invalid relative JSON pointer %q: invalid index manipulation", s)
+		}
+		index, err := strconv.Atoi(rest[:m])
+		if err != nil {
+			return rp, fmt.Errorf("invalid relative JSON pointer %q: %v", s, err)
+		}
+		rp.index = index
+		rp.hasIndex = true
+		rest = rest[m:]
+	}
+
+	if rest == "#" {
+		rp.hash = true
+		return rp, nil
+	}
+	ptr, err := jsonpointer.New(rest)
+	if err != nil {
+		return rp, fmt.Errorf("invalid relative JSON pointer %q: %v", s, err)
+	}
+	rp.ptr = ptr
+	return rp, nil
+}
+
+// resolve resolves the relative pointer against the base pointer. It returns the decoded tokens of the location that
+// is moved to, before applying the trailing JSON pointer.
+func (rp relativePointer) resolve(basePtr jsonpointer.Pointer) ([]string, error) {
+	base := basePtr.DecodedTokens()
+	if rp.up > len(base) {
+		return nil, fmt.Errorf("moving up %d levels from %q exceeds the root", rp.up, basePtr.String())
+	}
+	tks := append([]string{}, base[:len(base)-rp.up]...)
+	if rp.hasIndex {
+		if len(tks) == 0 {
+			return nil, fmt.Errorf("index manipulation on the root")
+		}
+		idx, err := strconv.Atoi(tks[len(tks)-1])
+		if err != nil || idx < 0 {
+			return nil, fmt.Errorf("index manipulation on a non-index token %q", tks[len(tks)-1])
+		}
+		idx += rp.index
+		if idx < 0 {
+			return nil, fmt.Errorf("index manipulation results in a negative index %d", idx)
+		}
+		tks[len(tks)-1] = strconv.Itoa(idx)
+	}
+	return tks, nil
+}
+
+// GetRelativePositions returns the positions of the values that the specified relative JSON pointers, e.g. "1/foo"
+// or "0-1", point to within the document, when resolved against the base pointer. The result is keyed by the
+// relative pointers, whose Ptr is the resolved pointer. The relative pointers that don't exist in the document are
+// omitted from the result.
+//
+// A relative pointer ending with "#" references the key or index of the location it moves to. For an object member,
+// the position is the one of the key. For an array element, which has no key in the document, the position is the
+// one of the element itself.
+//
+// It is an error if a relative pointer is malformed, moves up past the root, references the key of the root, or
+// manipulates the index of a location that isn't an array element.
+func GetRelativePositions(document string, base jsonpointer.Pointer, rels []string, opts ...Option) (map[string]JSONPointerPosition, error) {
+	rps := make([]relativePointer, len(rels))
+	locs := make([]jsonpointer.Pointer, len(rels))
+	targets := make([]jsonpointer.Pointer, len(rels))
+	var ptrs []jsonpointer.Pointer
+	for i, rel := range rels {
+		rp, err := parseRelativePointer(rel)
+		if err != nil {
+			return nil, err
+		}
+		tks, err := rp.resolve(base)
+		if err != nil {
+			return nil, fmt.Errorf("resolving relative JSON pointer %q: %v", rel, err)
+		}
+		if rp.hash && len(tks) == 0 {
+			return nil, fmt.Errorf("resolving relative JSON pointer %q: the root has no key or index", rel)
+		}
+		rps[i] = rp
+		locs[i] = jsonpointer.Pointer{}
+		if loc := newJSONPtr(tks); loc != nil {
+			locs[i] = *loc
+		}
+		ptrs = append(ptrs, locs[i])
+		if !rp.hash {
+			targets[i] = joinPointer(locs[i], rp.ptr)
+			ptrs = append(ptrs, targets[i])
+		}
+	}
+
+	m, err := GetPositions(document, ptrs, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := map[string]JSONPointerPosition{}
+	for i, rel := range rels {
+		rp := rps[i]
+		loc, ok := m[locs[i].String()]
+		if !ok {
+			continue
+		}
+		if rp.hasIndex && loc.KeyPosition != nil {
+			return nil, fmt.Errorf("resolving relative JSON pointer %q: index manipulation on the object member %q", rel, locs[i].String())
+		}
+		if rp.hash {
+			if loc.KeyPosition != nil {
+				loc.Position = *loc.KeyPosition
+			}
+			out[rel] = loc
+			continue
+		}
+		if pos, ok := m[targets[i].String()]; ok {
+			out[rel] = pos
+		}
+	}
+	return out, nil
+}
+
+// GetPositionsUnder returns the positions of the values that the pointers point to within the value of the base
+// pointer, i.e. each pointer is prepended with the base pointer before resolved, e.g. "/metadata/name" under "/spec"
+// is "/spec/metadata/name". The result is keyed by the pointers relative to the base pointer, whose Ptr is the
+// resolved pointer. It is an error if the base pointer doesn't point to an object or array within the document.
+func GetPositionsUnder(document string, base jsonpointer.Pointer, rels []jsonpointer.Pointer, opts ...Option) (map[string]JSONPointerPosition, error) {
+	ptrs := []jsonpointer.Pointer{base}
+	for _, rel := range rels {
+		ptrs = append(ptrs, joinPointer(base, rel))
+	}
+	m, err := GetPositions(document, ptrs, opts...)
+	if err != nil {
+		return nil, err
+	}
+	pos, ok := m[base.String()]
+	if !ok || pos.Missing {
+		return nil, fmt.Errorf("base pointer %q doesn't exist in the document", base.String())
+	}
+	if pos.ClosePosition == nil {
+		return nil, fmt.Errorf("base pointer %q doesn't point to an object or array", base.String())
+	}
+
+	// The base pointer is only requested to be checked, so that neither itself nor its members expanded by
+	// WithExpandObjects or WithExpandArrays are in the result, unless the relative pointers request them as well
+	o := newOptions(opts)
+	patterns := o.patterns(ptrs[1:])
+	requested := func(ptr jsonpointer.Pointer) bool {
+		if matchAny(patterns, ptr) {
+			return true
+		}
+		tks := ptr.DecodedTokens()
+		return (o.expandObjects || o.expandArrays) && len(tks) != 0 && matchAny(patterns, NewPointer(tks[:len(tks)-1]...))
+	}
+	out := map[string]JSONPointerPosition{}
+	prefix := base.String()
+	for k, pos := range m {
+		if !requested(pos.Ptr) {
+			continue
+		}
+		// The expanded pointers, e.g. of the wildcards, are all under the base pointer
+		out[strings.TrimPrefix(k, prefix)] = pos
+	}
+	return out, nil
+}
+
+func joinPointer(ptr, rel jsonpointer.Pointer) jsonpointer.Pointer {
+	joined, _ := jsonpointer.New(ptr.String() + rel.String())
+	return joined
+}
@@ -0,0 +1,257 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRelativePointer(t *testing.T) {
+	cases := []struct {
+		input  string
+		expect relativePointer
+		err    bool
+	}{
+		{
+			input:  "0",
+			expect: relativePointer{},
+		},
+		{
+			input:  "1/0",
+			expect: relativePointer{up: 1, ptr: mustPointer("/0")},
+		},
+		{
+			input:  "2/highly/nested/objects",
+			expect: relativePointer{up: 2, ptr: mustPointer("/highly/nested/objects")},
+		},
+		{
+			input:  "0#",
+			expect: relativePointer{hash: true},
+		},
+		{
+			input:  "0-1",
+			expect: relativePointer{index: -1, hasIndex: true},
+		},
+		{
+			input:  "1+2#",
+			expect: relativePointer{up: 1, index: 2, hasIndex: true, hash: true},
+		},
+		{
+			input: "",
+			err:   true,
+		},
+		{
+			input: "01",
+			err:   true,
+		},
+		{
+			input: "0+",
+			err:   true,
+		},
+		{
+			input: "0+01",
+			err:   true,
+		},
+		{
+			input: "1foo",
+			err:   true,
+		},
+		{
+			input: "0#/a",
+			err:   true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.input, func(t *testing.T) {
+			rp, err := parseRelativePointer(tt.input)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, rp)
+		})
+	}
+}
+
+func TestGetRelativePositions(t *testing.T) {
+	input := `{"foo": ["bar", "baz"], "highly": {"nested": {"objects": true}}}`
+
+	cases := []struct {
+		name   string
+		base   string
+		rels   []string
+		expect map[string]JSONPointerPosition
+		err    bool
+	}{
+		{
+			name: "array element",
+			base: "/foo/1",
+			rels: []string{"0", "1/0", "0-1", "0+1"},
+			expect: map[string]JSONPointerPosition{
+				"0": {
+					Ptr:            mustPointer("/foo/1"),
+					Depth:          2,
+					Kind:           KindString,
+					Position:       Position{Line: 1, Column: 17, Offset: 16},
+					EndPosition:    Position{Line: 1, Column: 21, Offset: 20},
+					ByteLength:     5,
+					ParentPosition: &Position{Line: 1, Column: 9, Offset: 8},
+					CommaPosition:  &Position{Line: 1, Column: 15, Offset: 14},
+				},
+				"1/0": {
+					Ptr:            mustPointer("/foo/0"),
+					Depth:          2,
+					Kind:           KindString,
+					Position:       Position{Line: 1, Column: 10, Offset: 9},
+					EndPosition:    Position{Line: 1, Column: 14, Offset: 13},
+					ByteLength:     5,
+					ParentPosition: &Position{Line: 1, Column: 9, Offset: 8},
+					CommaPosition:  &Position{Line: 1, Column: 15, Offset: 14},
+				},
+				"0-1": {
+					Ptr:            mustPointer("/foo/0"),
+					Depth:          2,
+					Kind:           KindString,
+					Position:       Position{Line: 1, Column: 10, Offset: 9},
+					EndPosition:    Position{Line: 1, Column: 14, Offset: 13},
+					ByteLength:     5,
+					ParentPosition: &Position{Line: 1, Column: 9, Offset: 8},
+					CommaPosition:  &Position{Line: 1, Column: 15, Offset: 14},
+				},
+			},
+		},
+		{
+			name: "object member",
+			base: "/foo/1",
+			rels: []string{"2/highly/nested/objects", "1#", "0#"},
+			expect: map[string]JSONPointerPosition{
+				"2/highly/nested/objects": {
+					Ptr:            mustPointer("/highly/nested/objects"),
+					Depth:          3,
+					Kind:           KindBoolean,
+					Position:       Position{Line: 1, Column: 58, Offset: 57},
+					EndPosition:    Position{Line: 1, Column: 61, Offset: 60},
+					ByteLength:     4,
+					KeyPosition:    &Position{Line: 1, Column: 47, Offset: 46},
+					KeyEndPosition: &Position{Line: 1, Column: 55, Offset: 54},
+					ColonPosition:  &Position{Line: 1, Column: 56, Offset: 55},
+					ParentPosition: &Position{Line: 1, Column: 46, Offset: 45},
+				},
+				"1#": {
+					Ptr:            mustPointer("/foo"),
+					Depth:          1,
+					Kind:           KindArray,
+					Position:       Position{Line: 1, Column: 2, Offset: 1},
+					EndPosition:    Position{Line: 1, Column: 22, Offset: 21},
+					ClosePosition:  &Position{Line: 1, Column: 22, Offset: 21},
+					ByteLength:     14,
+					KeyPosition:    &Position{Line: 1, Column: 2, Offset: 1},
+					KeyEndPosition: &Position{Line: 1, Column: 6, Offset: 5},
+					ColonPosition:  &Position{Line: 1, Column: 7, Offset: 6},
+					ParentPosition: &Position{Line: 1, Column: 1, Offset: 0},
+					CommaPosition:  &Position{Line: 1, Column: 23, Offset: 22},
+				},
+				"0#": {
+					Ptr:            mustPointer("/foo/1"),
+					Depth:          2,
+					Kind:           KindString,
+					Position:       Position{Line: 1, Column: 17, Offset: 16},
+					EndPosition:    Position{Line: 1, Column: 21, Offset: 20},
+					ByteLength:     5,
+					ParentPosition: &Position{Line: 1, Column: 9, Offset: 8},
+					CommaPosition:  &Position{Line: 1, Column: 15, Offset: 14},
+				},
+			},
+		},
+		{
+			name: "over-pop",
+			base: "/foo/1",
+			rels: []string{"3"},
+			err:  true,
+		},
+		{
+			name: "key of the root",
+			base: "/foo",
+			rels: []string{"1#"},
+			err:  true,
+		},
+		{
+			name: "index manipulation on object member",
+			base: "/highly/nested",
+			rels: []string{"0+1"},
+			err:  true,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := GetRelativePositions(input, mustPointer(tt.base), tt.rels)
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, out)
+		})
+	}
+}
+
+func TestGetPositionsUnder(t *testing.T) {
+	input := `{"spec": {"name": "x", "ports": [80, 443]}, "name": "y", "kind": 1}`
+	all, err := GetAllPositions(input)
+	require.NoError(t, err)
+
+	out, err := GetPositionsUnder(input, mustPointer("/spec"), []jsonpointer.Pointer{mustPointer("/name"), mustPointer("/ports/*"), mustPointer("/x")}, WithWildcards())
+	require.NoError(t, err)
+	require.Equal(t, map[string]JSONPointerPosition{
+		"/name":    all["/spec/name"],
+		"/ports/0": all["/spec/ports/0"],
+		"/ports/1": all["/spec/ports/1"],
+	}, out)
+
+	out, err = GetPositionsUnder(input, mustPointer("/spec/ports"), []jsonpointer.Pointer{mustPointer(""), mustPointer("/1")})
+	require.NoError(t, err)
+	require.Equal(t, map[string]JSONPointerPosition{
+		"":   all["/spec/ports"],
+		"/1": all["/spec/ports/1"],
+	}, out)
+
+	out, err = GetPositionsUnder(input, mustPointer(""), []jsonpointer.Pointer{mustPointer("/name")})
+	require.NoError(t, err)
+	require.Equal(t, map[string]JSONPointerPosition{"/name": all["/name"]}, out)
+
+	// Only the values that the relative pointers point to are expanded, not the base
+	out, err = GetPositionsUnder(input, mustPointer("/spec"), []jsonpointer.Pointer{mustPointer("/name")}, WithExpandObjects(), WithExpandArrays())
+	require.NoError(t, err)
+	require.Equal(t, map[string]JSONPointerPosition{"/name": all["/spec/name"]}, out)
+	out, err = GetPositionsUnder(input, mustPointer("/spec"), []jsonpointer.Pointer{mustPointer("/ports")}, WithExpandArrays())
+	require.NoError(t, err)
+	require.Equal(t, map[string]JSONPointerPosition{
+		"/ports":   all["/spec/ports"],
+		"/ports/0": all["/spec/ports/0"],
+		"/ports/1": all["/spec/ports/1"],
+	}, out)
+	out, err = GetPositionsUnder(input, mustPointer("/spec"), []jsonpointer.Pointer{mustPointer("")}, WithExpandObjects())
+	require.NoError(t, err)
+	require.Equal(t, map[string]JSONPointerPosition{
+		"":       all["/spec"],
+		"/name":  all["/spec/name"],
+		"/ports": all["/spec/ports"],
+	}, out)
+
+	for _, base := range []string{"/spec/name", "/kind", "/missing"} {
+		_, err = GetPositionsUnder(input, mustPointer(base), []jsonpointer.Pointer{mustPointer("/name")}, WithReportMissing())
+		require.Error(t, err, base)
+	}
+}
+
+func mustPointer(s string) jsonpointer.Pointer {
+	ptr, err := jsonpointer.New(s)
+	if err != nil {
+		panic(err)
+	}
+	return ptr
+}
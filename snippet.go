@@ -0,0 +1,101 @@
+package jsonpointerpos
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Snippet returns the source lines around the position within the document, followed by a caret line pointing at it,
+// like the diagnostics of a compiler, e.g.
+//
+//	1 | {
+//	2 |   "a": tru
+//	  |        ^
+//	3 | }
+//
+// Each line is prefixed with its line number, which is counted with the options as the reported positions. Up to
+// contextLines lines are included before and after the line of the position. Only the Offset of the position is used,
+// so that the snippet is right regardless of how its column is counted.
+//
+// The caret is aligned in display cells: East Asian wide characters (e.g. CJK and most emoji) occupy two cells, and the
+// combining marks occupy none. The tabs are expanded to spaces with WithTabWidth, otherwise they are kept in both the
+// source line and the caret line, so that the terminal aligns them alike.
+func Snippet(document string, pos Position, contextLines int, opts ...Option) string {
+	idx := NewLineIndex(document, opts...)
+	offset := pos.Offset - idx.opts.baseOffset()
+	if offset < 0 {
+		offset = 0
+	}
+	if offset > int64(len(document)) {
+		offset = int64(len(document))
+	}
+	i := sort.SearchInts(idx.starts, int(offset)+1) - 1
+	if contextLines < 0 {
+		contextLines = 0
+	}
+	first, last := i-contextLines, i+contextLines
+	if first < 0 {
+		first = 0
+	}
+	if last >= len(idx.starts) {
+		last = len(idx.starts) - 1
+	}
+
+	line, _ := idx.opts.start()
+	if idx.opts.zeroBased {
+		line--
+	}
+	width := len(strconv.Itoa(line + last))
+	var sb strings.Builder
+	for j := first; j <= last; j++ {
+		fmt.Fprintf(&sb, "%*d | %s\n", width, line+j, displayLine(idx.lineText(j), idx.opts.tabWidth, false))
+		if j == i {
+			// The offset may be at the LF of a CRLF
+			prefix := strings.TrimSuffix(document[idx.starts[i]:offset], "\r")
+			fmt.Fprintf(&sb, "%*s | %s^\n", width, "", displayLine(prefix, idx.opts.tabWidth, true))
+		}
+	}
+	return sb.String()
+}
+
+// lineText returns the text of the line of the index i, without the line break.
+func (idx *LineIndex) lineText(i int) string {
+	end := len(idx.document)
+	if i+1 < len(idx.starts) {
+		end = idx.starts[i+1]
+	}
+	s := idx.document[idx.starts[i]:end]
+	if idx.opts.unicodeLineTerminators && i+1 < len(idx.starts) {
+		s = strings.TrimSuffix(strings.TrimSuffix(s, "\u2028"), "\u2029")
+	}
+	s = strings.TrimSuffix(s, "\n")
+	return strings.TrimSuffix(s, "\r")
+}
+
+// displayLine returns the line for display, where the tabs are expanded to spaces if the tab width is more than 1.
+// If pad is true, the line is instead converted to the blanks that occupy the same display cells.
+func displayLine(s string, tabWidth int, pad bool) string {
+	var sb strings.Builder
+	var cells int
+	for _, r := range s {
+		switch {
+		case r == '\t' && tabWidth > 1:
+			n := tabWidth - cells%tabWidth
+			sb.WriteString(strings.Repeat(" ", n))
+			cells += n
+		case r == '\t':
+			sb.WriteByte('\t')
+			cells++
+		case pad:
+			n := runeWidth(r)
+			sb.WriteString(strings.Repeat(" ", n))
+			cells += n
+		default:
+			sb.WriteRune(r)
+			cells += runeWidth(r)
+		}
+	}
+	return sb.String()
+}
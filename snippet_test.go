@@ -0,0 +1,93 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnippet(t *testing.T) {
+	cases := []struct {
+		name         string
+		input        string
+		ptr          string
+		contextLines int
+		opts         []Option
+		expect       string
+	}{
+		{
+			name:         "context lines",
+			input:        "{\n  \"a\": 1,\n  \"b\": 2,\n  \"c\": 3\n}",
+			ptr:          "/b",
+			contextLines: 1,
+			expect: "2 |   \"a\": 1,\n" +
+				"3 |   \"b\": 2,\n" +
+				"  |        ^\n" +
+				"4 |   \"c\": 3\n",
+		},
+		{
+			name:         "clamped to the document",
+			input:        "{\r\n\"a\": 1}",
+			ptr:          "",
+			contextLines: 3,
+			expect: "1 | {\n" +
+				"  | ^\n" +
+				"2 | \"a\": 1}\n",
+		},
+		{
+			name:  "wide characters",
+			input: `{"名前😀é": 1}`,
+			ptr:   "/名前😀é",
+			expect: "1 | {\"名前😀é\": 1}\n" +
+				"  |             ^\n",
+		},
+		{
+			name:  "tabs kept",
+			input: "{\n\t\"a\":\t1}",
+			ptr:   "/a",
+			expect: "2 | \t\"a\":\t1}\n" +
+				"  | \t    \t^\n",
+		},
+		{
+			name:  "tabs expanded",
+			input: "{\n\t\"a\":\t1}",
+			ptr:   "/a",
+			opts:  []Option{WithTabWidth(4)},
+			expect: "2 |     \"a\":    1}\n" +
+				"  |             ^\n",
+		},
+		{
+			name:         "line number width",
+			input:        "[\n\n\n\n\n\n\n\n\n1]",
+			ptr:          "/0",
+			contextLines: 1,
+			expect: " 9 | \n" +
+				"10 | 1]\n" +
+				"   | ^\n",
+		},
+		{
+			name:  "zero-based",
+			input: "[\n1]",
+			ptr:   "/0",
+			opts:  []Option{WithZeroBased()},
+			expect: "1 | 1]\n" +
+				"  | ^\n",
+		},
+		{
+			name:  "base offset",
+			input: "[\n1]",
+			ptr:   "/0",
+			opts:  []Option{WithBaseOffset(100, 41, 5)},
+			expect: "42 | 1]\n" +
+				"   | ^\n",
+		},
+	}
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			pos, ok, err := GetPosition(tt.input, mustPointer(tt.ptr), tt.opts...)
+			require.NoError(t, err)
+			require.True(t, ok)
+			require.Equal(t, tt.expect, Snippet(tt.input, pos.Position, tt.contextLines, tt.opts...))
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package jsonpointerpos
+
+import (
+	"sort"
+)
+
+// SourceMapEntry is the position of a value within the document, keyed by its JSON pointer.
+type SourceMapEntry struct {
+	Pointer  string   `json:"pointer"`
+	Position Position `json:"position"`
+}
+
+// BuildSourceMap returns the positions of all the values within the document in the document order, i.e. by their
+// offsets, so that it's stable to be serialized and compared. It is like GetAllPositions, which covers the root value
+// and both the scalar values and the containers.
+func BuildSourceMap(document string, opts ...Option) ([]SourceMapEntry, error) {
+	m, err := GetAllPositions(document, opts...)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]SourceMapEntry, 0, len(m))
+	for ptr, pos := range m {
+		out = append(out, SourceMapEntry{Pointer: ptr, Position: pos.Position})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Position.Offset < out[j].Position.Offset
+	})
+	return out, nil
+}
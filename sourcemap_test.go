@@ -0,0 +1,32 @@
+package jsonpointerpos
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildSourceMap(t *testing.T) {
+	input := "{\n  \"b\": [1, {\"c\": null}],\n  \"a\": \"x\"\n}"
+	out, err := BuildSourceMap(input)
+	require.NoError(t, err)
+	require.Equal(t, []SourceMapEntry{
+		{Pointer: "", Position: Position{Line: 1, Column: 1, Offset: 0}},
+		{Pointer: "/b", Position: Position{Line: 2, Column: 8, Offset: 9}},
+		{Pointer: "/b/0", Position: Position{Line: 2, Column: 9, Offset: 10}},
+		{Pointer: "/b/1", Position: Position{Line: 2, Column: 12, Offset: 13}},
+		{Pointer: "/b/1/c", Position: Position{Line: 2, Column: 18, Offset: 19}},
+		{Pointer: "/a", Position: Position{Line: 3, Column: 8, Offset: 34}},
+	}, out)
+
+	b, err := json.Marshal(out[:2])
+	require.NoError(t, err)
+	require.JSONEq(t, `[
+  {"pointer": "", "position": {"line": 1, "column": 1, "offset": 0}},
+  {"pointer": "/b", "position": {"line": 2, "column": 8, "offset": 9}}
+]`, string(b))
+
+	_, err = BuildSourceMap(`{"a": }`)
+	require.Error(t, err)
+}
@@ -0,0 +1,94 @@
+package jsonpointerpos
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// JSONPointerValue is the position of a value together with the decoded value.
+type JSONPointerValue struct {
+	JSONPointerPosition
+	// Value is the decoded value, as by encoding/json into an any, except that the numbers are decoded as
+	// json.Number to keep their precision. It's nil for the missing pointers and the insertion point of "-".
+	Value any
+}
+
+// GetPositionsWithValues is like GetPositions, but returns the decoded values together with their positions. The
+// document is read only once, as the values are decoded from their raw text captured during the walk, which is also
+// set to the Raw of the result.
+func GetPositionsWithValues(document string, ptrs []jsonpointer.Pointer, opts ...Option) (map[string]JSONPointerValue, error) {
+	o := newOptions(append(opts[:len(opts):len(opts)], WithRaw()))
+	m, err := getPositions(newWalker(strings.NewReader(document), o), ptrs)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]JSONPointerValue{}
+	for k, pos := range m {
+		v := JSONPointerValue{JSONPointerPosition: pos}
+		// The insertion point of the "-" token has no value
+		if !pos.Missing && pos.ByteLength != 0 {
+			// The raw text might contain comments or trailing commas, which are masked by the token reader
+			v.Value, err = decodeValue(newTokenReader(strings.NewReader(pos.Raw), o, nil), o.firstDuplicateKey)
+			if err != nil {
+				return nil, err
+			}
+		}
+		out[k] = v
+	}
+	return out, nil
+}
+
+// decodeValue decodes the next value from the tokens of dec, in the same way as *json.Decoder with UseNumber decodes
+// it into an any, so that the documents in the syntax other than the standard JSON (e.g. WithJSON5) can be decoded.
+// The last occurrence of duplicate object keys is kept, or the first one if firstKey is true, as the positions are
+// reported with WithFirstDuplicateKey.
+func decodeValue(dec TokenReader, firstKey bool) (any, error) {
+	tk, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch tk {
+	case json.Delim('{'):
+		m := map[string]any{}
+		for dec.More() {
+			tk, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := tk.(string)
+			if !ok {
+				return nil, fmt.Errorf("invalid object key token %#v", tk)
+			}
+			v, err := decodeValue(dec, firstKey)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := m[key]; !ok || !firstKey {
+				m[key] = v
+			}
+		}
+		// Consumes the ending delim
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return m, nil
+	case json.Delim('['):
+		s := []any{}
+		for dec.More() {
+			v, err := decodeValue(dec, firstKey)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, v)
+		}
+		// Consumes the ending delim
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	return tk, nil
+}
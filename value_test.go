@@ -0,0 +1,55 @@
+package jsonpointerpos
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPositionsWithValues(t *testing.T) {
+	input := `{"a": {"b": 12345678901234567890, "c": [1.5, "x"]}, /* c */ "d": [null, true,],}`
+	ptrs := []jsonpointer.Pointer{mustPointer("/a"), mustPointer("/a/b"), mustPointer("/d"), mustPointer("/e")}
+
+	out, err := GetPositionsWithValues(input, ptrs, WithComments(), WithTrailingCommas(), WithReportMissing())
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"b": json.Number("12345678901234567890"),
+		"c": []any{json.Number("1.5"), "x"},
+	}, out["/a"].Value)
+	require.Equal(t, json.Number("12345678901234567890"), out["/a/b"].Value)
+	require.Equal(t, int64(12), out["/a/b"].Offset)
+	require.Equal(t, "12345678901234567890", out["/a/b"].Raw)
+	require.Equal(t, []any{nil, true}, out["/d"].Value)
+	require.True(t, out["/e"].Missing)
+	require.Nil(t, out["/e"].Value)
+}
+
+func TestGetPositionsWithValuesDuplicateKeys(t *testing.T) {
+	input := `{"a": {"b": 1, "b": 2}, "a": {"b": 3, "b": 4}}`
+	ptrs := []jsonpointer.Pointer{mustPointer(""), mustPointer("/a/b")}
+
+	out, err := GetPositionsWithValues(input, ptrs)
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"a": map[string]any{"b": json.Number("4")}}, out[""].Value)
+	require.Equal(t, json.Number("4"), out["/a/b"].Value)
+	require.Equal(t, int64(43), out["/a/b"].Offset)
+
+	out, err = GetPositionsWithValues(input, ptrs, WithFirstDuplicateKey())
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"a": map[string]any{"b": json.Number("1")}}, out[""].Value)
+	require.Equal(t, json.Number("1"), out["/a/b"].Value)
+	require.Equal(t, int64(12), out["/a/b"].Offset)
+}
+
+func TestGetPositionsWithValuesAppend(t *testing.T) {
+	input := `{"a": [1, 2], "b": []}`
+	ptrs := []jsonpointer.Pointer{mustPointer("/a/-"), mustPointer("/b/-"), mustPointer("/a/0")}
+	out, err := GetPositionsWithValues(input, ptrs)
+	require.NoError(t, err)
+	require.Nil(t, out["/a/-"].Value)
+	require.Equal(t, int64(11), out["/a/-"].Offset)
+	require.Nil(t, out["/b/-"].Value)
+	require.Equal(t, json.Number("1"), out["/a/0"].Value)
+}
@@ -0,0 +1,41 @@
+package jsonpointerpos
+
+import (
+	"strings"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// WalkPositions is like GetPositions, but calls fn with the position of each value that the pointers point to once
+// it's found, instead of collecting them into a map. The walk stops with the error returned by fn, if any.
+//
+// fn is called in document order, i.e. by the offsets where the values begin, so that an object/array is reported
+// before its members/elements. As a value is only found once it is read to its end, the values within a requested
+// object/array are buffered until the object/array is found. Each value is reported once, even if it's matched by more
+// than one pointer. The
+// first occurrence of duplicate object keys is reported as with WithFirstDuplicateKey, and the DuplicatePositions is
+// not set, as the later occurrences are not known yet when the first one is found.
+func WalkPositions(document string, ptrs []jsonpointer.Pointer, fn func(JSONPointerPosition) error, opts ...Option) error {
+	if len(ptrs) == 0 {
+		return nil
+	}
+	w := newWalker(strings.NewReader(document), newOptions(append(opts[:len(opts):len(opts)], WithFirstDuplicateKey())))
+	w.visit = fn
+	ptrs = w.pos.opts.patterns(ptrs)
+	tree := buildTokenTree(ptrs)
+	w.setPending(&tree, ptrs)
+	return w.walk(&tree)
+}
+
+// Walk calls fn with the pointer, the kind and the position of every value within the document, including the root
+// value, whose pointer is "". Unlike WalkPositions, fn is called in document order once a value begins, so that an
+// object/array is reported before its members/elements. The duplicate object keys are all reported. The walk stops
+// with the error returned by fn, if any.
+//
+// It's the streaming counterpart of GetAllPositions, which doesn't keep the walked values in memory.
+func Walk(document string, fn func(ptr jsonpointer.Pointer, kind Kind, pos Position) error, opts ...Option) error {
+	w := newWalker(strings.NewReader(document), newOptions(opts))
+	w.all = true
+	w.begin = fn
+	return w.walk(&tokenTree{})
+}
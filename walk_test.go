@@ -0,0 +1,107 @@
+package jsonpointerpos
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWalkPositions(t *testing.T) {
+	input := `{"a": {"b": 1, "c": [2, 3]}, "d": 4}`
+	ptrs := []jsonpointer.Pointer{mustPointer(""), mustPointer("/d"), mustPointer("/a"), mustPointer("/a/c/*"), mustPointer("/a/b")}
+
+	var got []string
+	err := WalkPositions(input, ptrs, func(pos JSONPointerPosition) error {
+		got = append(got, pos.Ptr.String())
+		return nil
+	}, WithWildcards())
+	require.NoError(t, err)
+	require.Equal(t, []string{"", "/a", "/a/b", "/a/c/0", "/a/c/1", "/d"}, got)
+
+	// The positions are the same as the ones returned by GetPositions
+	m, err := GetPositions(input, ptrs, WithWildcards())
+	require.NoError(t, err)
+	err = WalkPositions(input, ptrs, func(pos JSONPointerPosition) error {
+		require.Equal(t, m[pos.Ptr.String()], pos)
+		return nil
+	}, WithWildcards())
+	require.NoError(t, err)
+
+	// The walk stops with the error returned by the callback
+	errStop := errors.New("stop")
+	got = nil
+	err = WalkPositions(input, ptrs, func(pos JSONPointerPosition) error {
+		got = append(got, pos.Ptr.String())
+		if len(got) == 2 {
+			return errStop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, []string{"", "/a"}, got)
+
+	// The values within a requested object/array are reported after it, once it's found
+	got = nil
+	err = WalkPositions(input, []jsonpointer.Pointer{mustPointer("/a/c/1"), mustPointer("/d"), mustPointer("/a"), mustPointer("/a/b")}, func(pos JSONPointerPosition) error {
+		got = append(got, pos.Ptr.String())
+		if pos.Ptr.String() == "/a" {
+			require.Equal(t, Position{Line: 1, Column: 27, Offset: 26}, pos.EndPosition)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"/a", "/a/b", "/a/c/1", "/d"}, got)
+}
+
+func TestWalk(t *testing.T) {
+	input := `{"a": {"b": 1, "c": [true, null]}, "d": "x", "a": []}`
+	type value struct {
+		ptr  string
+		kind Kind
+		pos  Position
+	}
+	var got []value
+	err := Walk(input, func(ptr jsonpointer.Pointer, kind Kind, pos Position) error {
+		got = append(got, value{ptr: ptr.String(), kind: kind, pos: pos})
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []value{
+		{ptr: "", kind: KindObject, pos: Position{Line: 1, Column: 1, Offset: 0}},
+		{ptr: "/a", kind: KindObject, pos: Position{Line: 1, Column: 7, Offset: 6}},
+		{ptr: "/a/b", kind: KindNumber, pos: Position{Line: 1, Column: 13, Offset: 12}},
+		{ptr: "/a/c", kind: KindArray, pos: Position{Line: 1, Column: 21, Offset: 20}},
+		{ptr: "/a/c/0", kind: KindBoolean, pos: Position{Line: 1, Column: 22, Offset: 21}},
+		{ptr: "/a/c/1", kind: KindNull, pos: Position{Line: 1, Column: 28, Offset: 27}},
+		{ptr: "/d", kind: KindString, pos: Position{Line: 1, Column: 41, Offset: 40}},
+		{ptr: "/a", kind: KindArray, pos: Position{Line: 1, Column: 51, Offset: 50}},
+	}, got)
+
+	// The positions are the same as the ones returned by GetAllPositions, except for the first occurrence of the
+	// duplicate key, where the last one is reported
+	all, err := GetAllPositions(input)
+	require.NoError(t, err)
+	require.Len(t, all, 3)
+	for _, v := range []value{got[0], got[6], got[7]} {
+		require.Equal(t, all[v.ptr].Position, v.pos, v.ptr)
+		require.Equal(t, all[v.ptr].Kind, v.kind, v.ptr)
+	}
+
+	// The walk stops with the error returned by the callback
+	errStop := errors.New("stop")
+	var n int
+	err = Walk(input, func(ptr jsonpointer.Pointer, kind Kind, pos Position) error {
+		if n++; ptr.String() == "/a/c" {
+			return errStop
+		}
+		return nil
+	})
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, 4, n)
+
+	err = Walk(`[1, x]`, func(jsonpointer.Pointer, Kind, Position) error { return nil })
+	var perr *ParseError
+	require.ErrorAs(t, err, &perr)
+}
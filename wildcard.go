@@ -0,0 +1,99 @@
+package jsonpointerpos
+
+import "github.com/go-openapi/jsonpointer"
+
+const (
+	// wildcardToken is the reference token that matches any object key or array index with the WithWildcards option.
+	wildcardToken = "*"
+	// recursiveWildcardToken is the reference token that matches zero or more levels of object keys or array indexes
+	// with the WithWildcards option.
+	recursiveWildcardToken = "**"
+	// wildcardPattern and recursiveWildcardPattern are the tokens that the wildcards are converted to before the walk.
+	// They start with a byte that is invalid in UTF-8, so that they never equal a decoded object key, and a key of "*"
+	// or "**" is matched literally.
+	wildcardPattern          = "\xff*"
+	recursiveWildcardPattern = "\xff**"
+)
+
+// patterns converts the wildcard tokens of the pointers to the patterns, if the WithWildcards option is specified.
+// Otherwise, the pointers are returned as is, so that "*" and "**" are literal keys as in RFC 6901.
+func (o options) patterns(ptrs []jsonpointer.Pointer) []jsonpointer.Pointer {
+	if !o.wildcards {
+		return ptrs
+	}
+	out := make([]jsonpointer.Pointer, len(ptrs))
+	for i, ptr := range ptrs {
+		out[i] = ptr
+		tks := ptr.DecodedTokens()
+		converted := false
+		for j, tk := range tks {
+			switch tk {
+			case wildcardToken:
+				tks[j], converted = wildcardPattern, true
+			case recursiveWildcardToken:
+				tks[j], converted = recursiveWildcardPattern, true
+			}
+		}
+		if converted {
+			out[i] = NewPointer(tks...)
+		}
+	}
+	return out
+}
+
+// literal converts the patterns of the pointer back to the wildcard tokens, e.g. for the pointers returned by the
+// exported path parsers.
+func literal(ptr jsonpointer.Pointer) jsonpointer.Pointer {
+	if !hasWildcard(ptr) {
+		return ptr
+	}
+	tks := ptr.DecodedTokens()
+	for i, tk := range tks {
+		switch tk {
+		case wildcardPattern:
+			tks[i] = wildcardToken
+		case recursiveWildcardPattern:
+			tks[i] = recursiveWildcardToken
+		}
+	}
+	return NewPointer(tks...)
+}
+
+func hasWildcard(ptr jsonpointer.Pointer) bool {
+	for _, tk := range ptr.DecodedTokens() {
+		if tk == wildcardPattern || tk == recursiveWildcardPattern {
+			return true
+		}
+	}
+	return false
+}
+
+// matchWildcard reports whether the concrete pointer string matches the pattern pointer, which might contain wildcards.
+func matchWildcard(pattern jsonpointer.Pointer, concrete string) bool {
+	ptr, err := jsonpointer.New(concrete)
+	if err != nil {
+		return false
+	}
+	return matchTokens(pattern.DecodedTokens(), ptr.DecodedTokens())
+}
+
+func matchTokens(ptks, ctks []string) bool {
+	if len(ptks) == 0 {
+		return len(ctks) == 0
+	}
+	if ptks[0] == recursiveWildcardPattern {
+		for i := 0; i <= len(ctks); i++ {
+			if matchTokens(ptks[1:], ctks[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(ctks) == 0 {
+		return false
+	}
+	if ptks[0] != wildcardPattern && ptks[0] != ctks[0] {
+		return false
+	}
+	return matchTokens(ptks[1:], ctks[1:])
+}
@@ -0,0 +1,42 @@
+package jsonpointerpos
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/go-openapi/jsonpointer"
+)
+
+// WritePositions writes the positions of the values that the pointers point to within the document to w as a JSON
+// object, which is keyed by the pointers as GetPositions. The keys are sorted, and each value is encoded with the
+// field names of the json tags of JSONPointerPosition, along with its pointer.
+func WritePositions(w io.Writer, document string, ptrs []jsonpointer.Pointer, opts ...Option) error {
+	m, err := GetPositions(document, ptrs, opts...)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(m)
+}
+
+// MarshalJSON encodes the position with the field names of its json tags, where the pointers and the error are
+// encoded as strings.
+func (p JSONPointerPosition) MarshalJSON() ([]byte, error) {
+	type position JSONPointerPosition
+	v := struct {
+		Ptr string `json:"pointer"`
+		position
+		Err      string  `json:"error,omitempty"`
+		Ancestor *string `json:"ancestor,omitempty"`
+	}{
+		Ptr:      p.Ptr.String(),
+		position: position(p),
+	}
+	if p.Err != nil {
+		v.Err = p.Err.Error()
+	}
+	if p.Ancestor != nil {
+		ancestor := p.Ancestor.String()
+		v.Ancestor = &ancestor
+	}
+	return json.Marshal(v)
+}
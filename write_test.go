@@ -0,0 +1,57 @@
+package jsonpointerpos
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePositions(t *testing.T) {
+	input := "{\n  \"a\": [1, \"x\"],\n  \"b\": 5\n}"
+	ptrs := []jsonpointer.Pointer{mustPointer("/a/1"), mustPointer(""), mustPointer("/b/c")}
+
+	var buf bytes.Buffer
+	require.NoError(t, WritePositions(&buf, input, ptrs, WithReportMissing()))
+	require.JSONEq(t, `{
+  "": {
+    "pointer": "", "depth": 0, "kind": "object", "line": 1, "column": 1, "offset": 0,
+    "endPosition": {"line": 4, "column": 1, "offset": 28},
+    "closePosition": {"line": 4, "column": 1, "offset": 28},
+    "byteLength": 29
+  },
+  "/a/1": {
+    "pointer": "/a/1", "depth": 2, "kind": "string", "line": 2, "column": 12, "offset": 13,
+    "endPosition": {"line": 2, "column": 14, "offset": 15},
+    "byteLength": 3,
+    "parentPosition": {"line": 2, "column": 8, "offset": 9},
+    "commaPosition": {"line": 2, "column": 10, "offset": 11}
+  },
+  "/b/c": {
+    "pointer": "/b/c", "depth": 2, "line": 0, "column": 0, "offset": 0,
+    "endPosition": {"line": 0, "column": 0, "offset": 0},
+    "byteLength": 0,
+    "missing": true,
+    "missingReason": "not an object or array",
+    "error": "pointer \"/b/c\" traverses the number at \"/b\""
+  }
+}`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, WritePositions(&buf, input, []jsonpointer.Pointer{mustPointer("/a/2/x")}, WithInsertPositions()))
+	require.JSONEq(t, `{
+  "/a/2/x": {
+    "pointer": "/a/2/x", "depth": 3, "line": 0, "column": 0, "offset": 0,
+    "endPosition": {"line": 0, "column": 0, "offset": 0},
+    "byteLength": 0,
+    "missing": true,
+    "missingReason": "index out of range",
+    "ancestor": "/a",
+    "ancestorPosition": {"line": 2, "column": 8, "offset": 9},
+    "insertPosition": {"line": 2, "column": 15, "offset": 16}
+  }
+}`, buf.String())
+
+	require.Error(t, WritePositions(&buf, `{"a": }`, ptrs))
+}
@@ -0,0 +1,195 @@
+package jsonpointerpos
+
+import (
+	"sort"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/go-openapi/jsonpointer"
+	"gopkg.in/yaml.v3"
+)
+
+// GetPositionsYAML is like GetPositions, but for a YAML document, whose mappings and sequences are addressed by the
+// JSON pointers the same way as the JSON objects and arrays. Both the block and flow styles are supported. Aliases are
+// followed to their anchored nodes, while the reported position is the one of the alias itself. Only the first
+// document of a multi-document stream is addressed.
+//
+// The position of a value is the one of its first character, e.g. the first key of a block mapping, or the "-" of
+// the first entry of a block sequence. The EndPosition, ByteLength, ColonPosition, ParentPosition and Raw are not
+// set.
+// Wildcards are not supported.
+func GetPositionsYAML(document string, ptrs []jsonpointer.Pointer, opts ...Option) (map[string]JSONPointerPosition, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(document), &doc); err != nil {
+		return nil, err
+	}
+	// An empty document, e.g. of only whitespaces or comments, has no root value, where the node is left zero
+	var root *yaml.Node
+	switch {
+	case doc.Kind == yaml.DocumentNode && len(doc.Content) != 0:
+		root = doc.Content[0]
+	case doc.Kind != yaml.DocumentNode && doc.Line != 0:
+		root = &doc
+	}
+
+	type found struct {
+		ptr       jsonpointer.Pointer
+		value     int
+		key       int
+		hasKeyPos bool
+		kind      Kind
+	}
+	o := newOptions(opts)
+	lines := yamlLineStarts(document)
+	var founds []found
+	var offsets []int
+	var missings []JSONPointerPosition
+	for _, ptr := range ptrs {
+		var value, key *yaml.Node
+		reason := MissingUnknown
+		if root != nil {
+			value, key, reason = findYAMLNode(root, ptr.DecodedTokens())
+		}
+		if value == nil {
+			missings = append(missings, JSONPointerPosition{Ptr: ptr, Depth: len(ptr.DecodedTokens()), Missing: true, MissingReason: reason})
+			continue
+		}
+		f := found{ptr: ptr, value: yamlOffset(document, lines, value), kind: yamlKind(value)}
+		offsets = append(offsets, f.value)
+		if key != nil {
+			f.key = yamlOffset(document, lines, key)
+			f.hasKeyPos = true
+			offsets = append(offsets, f.key)
+		}
+		founds = append(founds, f)
+	}
+
+	// The positioner resolves the offsets in non-decreasing order
+	sort.Ints(offsets)
+	pos := newPositioner(nil, o)
+	pos.buf = []byte(document)
+	for _, offset := range offsets {
+		pos.mark(int64(offset))
+	}
+
+	out := map[string]JSONPointerPosition{}
+	for _, f := range founds {
+		jpos := JSONPointerPosition{
+			Ptr:      f.ptr,
+			Depth:    len(f.ptr.DecodedTokens()),
+			Kind:     f.kind,
+			Position: pos.positions[int64(f.value)],
+		}
+		if f.hasKeyPos {
+			keyPos := pos.positions[int64(f.key)]
+			jpos.KeyPosition = &keyPos
+		}
+		out[f.ptr.String()] = jpos
+	}
+	if o.errorOnMissing {
+		if err := notFound(out, ptrs); err != nil {
+			return nil, err
+		}
+	}
+	if o.reportMissing {
+		for _, missing := range missings {
+			out[missing.Ptr.String()] = missing
+		}
+	}
+	return out, nil
+}
+
+// yamlKind returns the JSON type of the node as it would be decoded to JSON, e.g. KindNumber for an !!int or !!float.
+func yamlKind(node *yaml.Node) Kind {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		return KindObject
+	case yaml.SequenceNode:
+		return KindArray
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!int", "!!float":
+			return KindNumber
+		case "!!bool":
+			return KindBoolean
+		case "!!null":
+			return KindNull
+		default:
+			return KindString
+		}
+	default:
+		return KindUnknown
+	}
+}
+
+// findYAMLNode returns the node that the decoded tokens point to, and the key node if it's a mapping value.
+// It returns nil and the reason if the tokens don't exist.
+func findYAMLNode(node *yaml.Node, tks []string) (value, key *yaml.Node, reason MissingReason) {
+	for _, tk := range tks {
+		target := node
+		if target.Kind == yaml.AliasNode {
+			target = target.Alias
+		}
+		switch target.Kind {
+		case yaml.MappingNode:
+			value, key = nil, nil
+			for i := 0; i+1 < len(target.Content); i += 2 {
+				if target.Content[i].Value == tk {
+					key, value = target.Content[i], target.Content[i+1]
+					break
+				}
+			}
+			if value == nil {
+				return nil, nil, MissingKey
+			}
+		case yaml.SequenceNode:
+			idx, err := strconv.Atoi(tk)
+			if err != nil || idx < 0 || idx >= len(target.Content) || strconv.Itoa(idx) != tk {
+				return nil, nil, indexReason(tk)
+			}
+			key, value = nil, target.Content[idx]
+		default:
+			return nil, nil, MissingNotContainer
+		}
+		node = value
+	}
+	return node, key, MissingUnknown
+}
+
+// yamlLineStarts returns the byte offsets of the start of each line, where the line breaks are the ones recognized by
+// the YAML parser.
+func yamlLineStarts(document string) []int {
+	starts := []int{0}
+	for i := 0; i < len(document); {
+		r, size := utf8.DecodeRuneInString(document[i:])
+		i += size
+		switch r {
+		case '\r':
+			if i < len(document) && document[i] == '\n' {
+				i++
+			}
+			starts = append(starts, i)
+		case '\n', '\u0085', '\u2028', '\u2029':
+			starts = append(starts, i)
+		}
+	}
+	return starts
+}
+
+// yamlOffset converts the line and column of the node, which are counted in characters starting at 1, to the byte
+// offset into the document.
+func yamlOffset(document string, lineStarts []int, node *yaml.Node) int {
+	offset := lineStarts[node.Line-1]
+	// The BOM is consumed by the YAML parser without being counted as a column
+	if node.Line == 1 && len(document) >= len(bom) && document[:len(bom)] == string(bom) {
+		offset += len(bom)
+	}
+	for i := 1; i < node.Column && offset < len(document); i++ {
+		_, size := utf8.DecodeRuneInString(document[offset:])
+		offset += size
+	}
+	return offset
+}
@@ -0,0 +1,175 @@
+package jsonpointerpos
+
+import (
+	"testing"
+
+	"github.com/go-openapi/jsonpointer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPositionsYAML(t *testing.T) {
+	cases := []struct {
+		name   string
+		input  string
+		ptrs   []string
+		opts   []Option
+		expect map[string]JSONPointerPosition
+	}{
+		{
+			name: "block style",
+			input: `foo:
+  bar: 1
+  baz:
+    - a
+    - b
+`,
+			ptrs: []string{"/foo", "/foo/bar", "/foo/baz", "/foo/baz/1", "/foo/qux"},
+			expect: map[string]JSONPointerPosition{
+				"/foo": {
+					Ptr:         mustPointer("/foo"),
+					Depth:       1,
+					Kind:        KindObject,
+					Position:    Position{Line: 2, Column: 3, Offset: 7},
+					KeyPosition: &Position{Line: 1, Column: 1, Offset: 0},
+				},
+				"/foo/bar": {
+					Ptr:         mustPointer("/foo/bar"),
+					Depth:       2,
+					Kind:        KindNumber,
+					Position:    Position{Line: 2, Column: 8, Offset: 12},
+					KeyPosition: &Position{Line: 2, Column: 3, Offset: 7},
+				},
+				"/foo/baz": {
+					Ptr:         mustPointer("/foo/baz"),
+					Depth:       2,
+					Kind:        KindArray,
+					Position:    Position{Line: 4, Column: 5, Offset: 25},
+					KeyPosition: &Position{Line: 3, Column: 3, Offset: 16},
+				},
+				"/foo/baz/1": {
+					Ptr:      mustPointer("/foo/baz/1"),
+					Depth:    3,
+					Kind:     KindString,
+					Position: Position{Line: 5, Column: 7, Offset: 35},
+				},
+			},
+		},
+		{
+			name:  "flow style",
+			input: `{"foo": [1, {bar: "é"}], qux: null}`,
+			ptrs:  []string{"", "/foo/1/bar", "/qux"},
+			expect: map[string]JSONPointerPosition{
+				"": {
+					Ptr:      mustPointer(""),
+					Kind:     KindObject,
+					Position: Position{Line: 1, Column: 1, Offset: 0},
+				},
+				"/foo/1/bar": {
+					Ptr:         mustPointer("/foo/1/bar"),
+					Depth:       3,
+					Kind:        KindString,
+					Position:    Position{Line: 1, Column: 19, Offset: 18},
+					KeyPosition: &Position{Line: 1, Column: 14, Offset: 13},
+				},
+				"/qux": {
+					Ptr:         mustPointer("/qux"),
+					Depth:       1,
+					Kind:        KindNull,
+					Position:    Position{Line: 1, Column: 31, Offset: 31},
+					KeyPosition: &Position{Line: 1, Column: 26, Offset: 26},
+				},
+			},
+		},
+		{
+			name: "alias",
+			input: `base: &base
+  a: 1
+derived: *base
+`,
+			ptrs: []string{"/derived", "/derived/a"},
+			expect: map[string]JSONPointerPosition{
+				"/derived": {
+					Ptr:         mustPointer("/derived"),
+					Depth:       1,
+					Kind:        KindObject,
+					Position:    Position{Line: 3, Column: 10, Offset: 28},
+					KeyPosition: &Position{Line: 3, Column: 1, Offset: 19},
+				},
+				"/derived/a": {
+					Ptr:         mustPointer("/derived/a"),
+					Depth:       2,
+					Kind:        KindNumber,
+					Position:    Position{Line: 2, Column: 6, Offset: 17},
+					KeyPosition: &Position{Line: 2, Column: 3, Offset: 14},
+				},
+			},
+		},
+		{
+			name:  "zero-based and CRLF",
+			input: "a: 1\r\nb: 2\r\n",
+			ptrs:  []string{"/b"},
+			opts:  []Option{WithZeroBased()},
+			expect: map[string]JSONPointerPosition{
+				"/b": {
+					Ptr:         mustPointer("/b"),
+					Depth:       1,
+					Kind:        KindNumber,
+					Position:    Position{Line: 1, Column: 3, Offset: 9},
+					KeyPosition: &Position{Line: 1, Column: 0, Offset: 6},
+				},
+			},
+		},
+		{
+			name:  "report missing",
+			input: "a: 1\n",
+			ptrs:  []string{"/b"},
+			opts:  []Option{WithReportMissing()},
+			expect: map[string]JSONPointerPosition{
+				"/b": {
+					Ptr:           mustPointer("/b"),
+					Depth:         1,
+					Missing:       true,
+					MissingReason: MissingKey,
+				},
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var ptrs []jsonpointer.Pointer
+			for _, v := range tt.ptrs {
+				ptrs = append(ptrs, mustPointer(v))
+			}
+			out, err := GetPositionsYAML(tt.input, ptrs, tt.opts...)
+			require.NoError(t, err)
+			require.Equal(t, tt.expect, out)
+		})
+	}
+}
+
+func TestGetPositionsYAMLEmpty(t *testing.T) {
+	ptrs := []jsonpointer.Pointer{mustPointer(""), mustPointer("/a")}
+	for _, input := range []string{"", "  \n\n  ", "# only a comment\n", "\uFEFF", "\uFEFF# c"} {
+		t.Run(input, func(t *testing.T) {
+			out, err := GetPositionsYAML(input, ptrs)
+			require.NoError(t, err)
+			require.Empty(t, out)
+
+			out, err = GetPositionsYAML(input, ptrs, WithReportMissing())
+			require.NoError(t, err)
+			require.Len(t, out, 2)
+			require.True(t, out[""].Missing)
+			require.True(t, out["/a"].Missing)
+
+			_, err = GetPositionsYAML(input, ptrs, WithErrorOnMissing())
+			var nerr *ErrPointerNotFound
+			require.ErrorAs(t, err, &nerr)
+		})
+	}
+
+	// The explicit document of a null value has a root value
+	out, err := GetPositionsYAML("---\n", ptrs[:1])
+	require.NoError(t, err)
+	require.Equal(t, KindNull, out[""].Kind)
+}